@@ -0,0 +1,130 @@
+package mixpanel
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// GetAnnotations lists the annotations between fromDate and toDate, both in the
+// format yyyy-mm-dd.
+func (req *Request) GetAnnotations(fromDate string, toDate string) (string, error) {
+	return req.createChecked(false, "annotations", "", map[string]string{
+		"from_date": fromDate,
+		"to_date":   toDate,
+	})
+}
+
+// CreateAnnotation creates an annotation at date, in the format
+// yyyy-mm-dd hh:mm:ss, and returns the raw response.
+func (req *Request) CreateAnnotation(date string, description string) ([]byte, error) {
+	req.CreateRequest(false, "annotations", "create", 0, map[string]string{
+		"date":        date,
+		"description": description,
+	})
+	return req.DoPost(false)
+}
+
+// DeleteAnnotation deletes the annotation with the given id and returns the raw response.
+func (req *Request) DeleteAnnotation(id int) ([]byte, error) {
+	req.CreateRequest(false, "annotations", "delete", 0, map[string]string{
+		"id": strconv.Itoa(id),
+	})
+	return req.DoPost(false)
+}
+
+// annotationLayout is the date format of annotations.
+const annotationLayout = "2006-01-02 15:04:05"
+
+// Annotation is a note on a date in the project's charts.
+type Annotation struct {
+	ID          int    `json:"id,omitempty"`
+	Date        string `json:"date"`
+	Description string `json:"description"`
+}
+
+// annotationDate returns date in the yyyy-mm-dd hh:mm:ss format, adding a
+// midnight time to a bare yyyy-mm-dd date.
+func annotationDate(date string) string {
+	if len(date) == len(DateFormat) {
+		return date + " 00:00:00"
+	}
+	return date
+}
+
+// key identifies an annotation by date and description for syncing.
+func (a Annotation) key() string {
+	return annotationDate(a.Date) + "\x00" + a.Description
+}
+
+// ListAnnotations executes GetAnnotations and decodes the result.
+func (req *Request) ListAnnotations(r DateRange) ([]Annotation, error) {
+	if _, err := req.GetAnnotations(r.From.Format(DateFormat), r.To.Format(DateFormat)); err != nil {
+		return nil, err
+	}
+	var out struct {
+		Annotations []Annotation `json:"annotations"`
+	}
+	if err := req.DoJSON(false, &out); err != nil {
+		return nil, err
+	}
+	return out.Annotations, nil
+}
+
+// SyncAnnotations makes the annotations between the earliest and latest date
+// of desired match desired, keyed by date and description: missing ones are
+// created and others in that window are deleted. Running it again with the
+// same input changes nothing.
+func (req *Request) SyncAnnotations(desired []Annotation) error {
+	if len(desired) == 0 {
+		return nil
+	}
+	var r DateRange
+	for i, a := range desired {
+		t, err := time.Parse(annotationLayout, annotationDate(a.Date))
+		if err != nil {
+			return fmt.Errorf("mixpanel: invalid annotation date %q: %v", a.Date, err)
+		}
+		if i == 0 || t.Before(r.From) {
+			r.From = t
+		}
+		if i == 0 || t.After(r.To) {
+			r.To = t
+		}
+	}
+	return req.SyncAnnotationsRange(r, desired)
+}
+
+// SyncAnnotationsRange is SyncAnnotations over the explicit window r, so that
+// annotations in r can be deleted even when no desired annotation is near them.
+func (req *Request) SyncAnnotationsRange(r DateRange, desired []Annotation) error {
+	existing, err := req.ListAnnotations(r)
+	if err != nil {
+		return err
+	}
+	want := make(map[string]Annotation, len(desired))
+	for _, a := range desired {
+		want[a.key()] = a
+	}
+	have := make(map[string]bool, len(existing))
+	for _, a := range existing {
+		k := a.key()
+		if _, ok := want[k]; ok && !have[k] {
+			have[k] = true
+			continue
+		}
+		// Not desired, or a duplicate of one already kept.
+		if _, err := req.DeleteAnnotation(a.ID); err != nil {
+			return err
+		}
+	}
+	for k, a := range want {
+		if have[k] {
+			continue
+		}
+		if _, err := req.CreateAnnotation(annotationDate(a.Date), a.Description); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,244 @@
+package mixpanel
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// Defaults used by NewAsyncTracker for zero AsyncConfig fields.
+const (
+	DefaultAsyncBatchSize     = 500
+	DefaultAsyncFlushInterval = 5 * time.Second
+	DefaultAsyncWorkers       = 1
+)
+
+// ErrTrackerClosed is returned by Enqueue after the AsyncTracker is closed.
+var ErrTrackerClosed = errors.New("mixpanel: async tracker closed")
+
+// AsyncConfig configures an AsyncTracker.
+type AsyncConfig struct {
+	// BatchSize is the number of events sent per /import request, at most
+	// ImportBatchSize.
+	BatchSize int
+	// FlushInterval is the longest an event waits in the buffer before being sent.
+	FlushInterval time.Duration
+	// Workers is the number of batches sent concurrently.
+	Workers int
+	// QueueSize is the number of events buffered before Enqueue blocks.
+	// Defaults to BatchSize.
+	QueueSize int
+	// OnError, if set, is called with every batch that failed to send and the
+	// error, with `time` and `$insert_id` filled in as they were sent, so that
+	// a re-enqueued event is deduplicated by Mixpanel. It runs on its own
+	// goroutine, one call at a time, and failures queue up without bound while
+	// it runs, so it may block or re-enqueue the batch with Enqueue without
+	// stalling the workers.
+	OnError func(batch []Event, err error)
+}
+
+// asyncFailure is a batch that failed to send.
+type asyncFailure struct {
+	batch []Event
+	err   error
+}
+
+// AsyncTracker buffers events and sends them to /import in the background,
+// whenever BatchSize events are buffered or FlushInterval has passed. It is
+// safe for concurrent use.
+type AsyncTracker struct {
+	client *Client
+	cfg    AsyncConfig
+
+	mu     sync.RWMutex // guards closed and sends on events
+	closed bool
+	events chan Event
+
+	flush    chan chan struct{}
+	batches  chan []Event
+	inflight sync.WaitGroup // batches handed to workers but not yet sent
+	done     sync.WaitGroup // collector and workers
+
+	failMu     sync.Mutex // guards failures and failClosed
+	failures   []asyncFailure
+	failClosed bool
+	failSignal chan struct{} // wakes report when failures are queued or closed
+	reported   chan struct{} // closed once every failure has been reported
+}
+
+// NewAsyncTracker starts an AsyncTracker sending events through c. Close
+// must be called to flush the remaining events and stop it.
+func (c *Client) NewAsyncTracker(cfg AsyncConfig) *AsyncTracker {
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = DefaultAsyncBatchSize
+	}
+	if cfg.BatchSize > ImportBatchSize {
+		cfg.BatchSize = ImportBatchSize
+	}
+	if cfg.FlushInterval <= 0 {
+		cfg.FlushInterval = DefaultAsyncFlushInterval
+	}
+	if cfg.Workers <= 0 {
+		cfg.Workers = DefaultAsyncWorkers
+	}
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = cfg.BatchSize
+	}
+	t := &AsyncTracker{
+		client:     c,
+		cfg:        cfg,
+		events:     make(chan Event, cfg.QueueSize),
+		flush:      make(chan chan struct{}),
+		batches:    make(chan []Event),
+		failSignal: make(chan struct{}, 1),
+		reported:   make(chan struct{}),
+	}
+	t.done.Add(1 + cfg.Workers)
+	go t.collect()
+	for i := 0; i < cfg.Workers; i++ {
+		go t.work()
+	}
+	go t.report()
+	return t
+}
+
+// Enqueue buffers e for sending. It blocks while the buffer is full. An
+// event without a time, either Time or a `time` property, is stamped with the
+// current time here rather than when its batch is sent.
+func (t *AsyncTracker) Enqueue(e Event) error {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	if t.closed {
+		return ErrTrackerClosed
+	}
+	if _, ok := e.Properties["time"]; !ok && e.Time.IsZero() {
+		e.Time = t.client.config.now()
+	}
+	t.events <- e
+	return nil
+}
+
+// Flush sends all buffered events and waits until they have been sent.
+func (t *AsyncTracker) Flush() {
+	t.mu.RLock()
+	if t.closed {
+		t.mu.RUnlock()
+		return
+	}
+	ack := make(chan struct{})
+	t.flush <- ack
+	t.mu.RUnlock()
+	<-ack
+	t.inflight.Wait()
+}
+
+// Close stops accepting events, sends the buffered ones and waits for all
+// sends to finish and all failures to be reported to OnError.
+func (t *AsyncTracker) Close() {
+	t.mu.Lock()
+	if t.closed {
+		t.mu.Unlock()
+		return
+	}
+	t.closed = true
+	close(t.events)
+	t.mu.Unlock()
+	t.done.Wait()
+	t.failMu.Lock()
+	t.failClosed = true
+	t.failMu.Unlock()
+	t.signalFailure()
+	<-t.reported
+}
+
+// collect accumulates events into batches and hands them to the workers.
+func (t *AsyncTracker) collect() {
+	defer t.done.Done()
+	defer close(t.batches)
+
+	ticker := time.NewTicker(t.cfg.FlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]Event, 0, t.cfg.BatchSize)
+	dispatch := func() {
+		if len(batch) == 0 {
+			return
+		}
+		t.inflight.Add(1)
+		t.batches <- batch
+		batch = make([]Event, 0, t.cfg.BatchSize)
+	}
+	for {
+		select {
+		case e, ok := <-t.events:
+			if !ok {
+				dispatch()
+				return
+			}
+			batch = append(batch, e)
+			if len(batch) >= t.cfg.BatchSize {
+				dispatch()
+			}
+		case ack := <-t.flush:
+			// Take everything enqueued before the flush was requested.
+			for n := len(t.events); n > 0; n-- {
+				batch = append(batch, <-t.events)
+				if len(batch) >= t.cfg.BatchSize {
+					dispatch()
+				}
+			}
+			dispatch()
+			close(ack)
+		case <-ticker.C:
+			dispatch()
+		}
+	}
+}
+
+// work sends batches until the collector stops.
+func (t *AsyncTracker) work() {
+	defer t.done.Done()
+	config := &t.client.config
+	for batch := range t.batches {
+		// Prepare the batch here so that OnError sees the `time` and
+		// `$insert_id` that were sent.
+		batch = prepareImport(batch, config.now(), !config.DisableInsertID)
+		if err := t.client.ImportContext(context.Background(), batch); err != nil && t.cfg.OnError != nil {
+			t.failMu.Lock()
+			t.failures = append(t.failures, asyncFailure{batch: batch, err: err})
+			t.failMu.Unlock()
+			t.signalFailure()
+		}
+		t.inflight.Done()
+	}
+}
+
+// signalFailure wakes report without blocking.
+func (t *AsyncTracker) signalFailure() {
+	select {
+	case t.failSignal <- struct{}{}:
+	default:
+	}
+}
+
+// report passes failed batches to OnError until the tracker is closed.
+func (t *AsyncTracker) report() {
+	defer close(t.reported)
+	for {
+		t.failMu.Lock()
+		queued, closed := t.failures, t.failClosed
+		t.failures = nil
+		t.failMu.Unlock()
+		for _, f := range queued {
+			t.cfg.OnError(f.batch, f.err)
+		}
+		if len(queued) > 0 {
+			continue
+		}
+		if closed {
+			return
+		}
+		<-t.failSignal
+	}
+}
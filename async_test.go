@@ -0,0 +1,203 @@
+package mixpanel
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestAsync(t *testing.T) {
+	var mu sync.Mutex
+	n := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		var evs []json.RawMessage
+		json.Unmarshal(b, &evs)
+		mu.Lock()
+		n += len(evs)
+		mu.Unlock()
+		w.Write([]byte(`{"code":200}`))
+	}))
+	defer srv.Close()
+	c := NewClient(WithConfig(Config{APISecret: "s", IngestEndpoint: srv.URL}))
+	tr := c.NewAsyncTracker(AsyncConfig{BatchSize: 7, Workers: 3, FlushInterval: time.Hour})
+	var wg sync.WaitGroup
+	errs := make(chan error, 250)
+	for g := 0; g < 10; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 25; i++ {
+				if err := tr.Enqueue(Event{Name: "x"}); err != nil {
+					errs <- err
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Fatal(err)
+	}
+	tr.Flush()
+	mu.Lock()
+	if n != 250 {
+		t.Errorf("delivered %d events after Flush, want 250", n)
+	}
+	mu.Unlock()
+	if err := tr.Enqueue(Event{Name: "y"}); err != nil {
+		t.Fatal(err)
+	}
+	tr.Close()
+	mu.Lock()
+	if n != 251 {
+		t.Errorf("delivered %d events after Close, want 251", n)
+	}
+	mu.Unlock()
+	if err := tr.Enqueue(Event{}); err != ErrTrackerClosed {
+		t.Errorf("Enqueue after Close = %v, want %v", err, ErrTrackerClosed)
+	}
+}
+
+func TestAsyncOnErrorReenqueue(t *testing.T) {
+	var mu sync.Mutex
+	requests, delivered := 0, 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		requests++
+		if requests <= 5 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		delivered++
+		w.Write([]byte(`{"code":200}`))
+	}))
+	defer srv.Close()
+	c := NewClient(WithConfig(Config{APISecret: "s", IngestEndpoint: srv.URL}))
+	var tr *AsyncTracker
+	tr = c.NewAsyncTracker(AsyncConfig{BatchSize: 1, Workers: 1, QueueSize: 1, FlushInterval: time.Hour,
+		OnError: func(batch []Event, err error) {
+			for _, e := range batch {
+				if err := tr.Enqueue(e); err != nil {
+					t.Error(err)
+				}
+			}
+		}})
+	closed := make(chan struct{})
+	go func() {
+		for i := 0; i < 10; i++ {
+			if err := tr.Enqueue(Event{Name: "x"}); err != nil {
+				t.Error(err)
+			}
+		}
+		for {
+			mu.Lock()
+			n := delivered
+			mu.Unlock()
+			if n == 10 {
+				break
+			}
+			time.Sleep(time.Millisecond)
+		}
+		tr.Close()
+		close(closed)
+	}()
+	select {
+	case <-closed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("tracker deadlocked re-enqueueing failed batches from OnError")
+	}
+}
+
+func TestAsyncStampsAtEnqueue(t *testing.T) {
+	var mu sync.Mutex
+	now := time.Unix(1700000000, 0)
+	var times []int64
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var evs []struct {
+			Properties struct {
+				Time int64 `json:"time"`
+			} `json:"properties"`
+		}
+		json.NewDecoder(r.Body).Decode(&evs)
+		mu.Lock()
+		for _, e := range evs {
+			times = append(times, e.Properties.Time)
+		}
+		mu.Unlock()
+		w.Write([]byte(`{"code":200}`))
+	}))
+	defer srv.Close()
+	clock := func() time.Time {
+		mu.Lock()
+		defer mu.Unlock()
+		return now
+	}
+	c := NewClient(WithConfig(Config{APISecret: "s", IngestEndpoint: srv.URL, Now: clock}))
+	tr := c.NewAsyncTracker(AsyncConfig{FlushInterval: time.Hour})
+	if err := tr.Enqueue(Event{Name: "x"}); err != nil {
+		t.Fatal(err)
+	}
+	mu.Lock()
+	now = now.Add(5 * time.Second)
+	mu.Unlock()
+	tr.Close()
+	mu.Lock()
+	defer mu.Unlock()
+	if len(times) != 1 || times[0] != 1700000000000 {
+		t.Errorf("sent times %v, want [1700000000000]", times)
+	}
+}
+
+func TestAsyncReenqueueKeepsInsertID(t *testing.T) {
+	var mu sync.Mutex
+	var ids []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var evs []Event
+		json.NewDecoder(r.Body).Decode(&evs)
+		mu.Lock()
+		defer mu.Unlock()
+		for _, e := range evs {
+			id, _ := e.Properties["$insert_id"].(string)
+			ids = append(ids, id)
+		}
+		if len(ids) == 1 {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Write([]byte(`{"code":200}`))
+	}))
+	defer srv.Close()
+	c := NewClient(WithConfig(Config{APISecret: "s", IngestEndpoint: srv.URL}))
+	retried := make(chan error, 1)
+	var tr *AsyncTracker
+	tr = c.NewAsyncTracker(AsyncConfig{FlushInterval: time.Hour,
+		OnError: func(batch []Event, err error) {
+			for _, e := range batch {
+				if _, ok := e.Properties["$insert_id"]; !ok {
+					retried <- errors.New("OnError got an event without $insert_id")
+					return
+				}
+				retried <- tr.Enqueue(e)
+			}
+		}})
+	if err := tr.Enqueue(Event{Name: "x", DistinctID: "u1"}); err != nil {
+		t.Fatal(err)
+	}
+	tr.Flush()
+	if err := <-retried; err != nil {
+		t.Fatal(err)
+	}
+	tr.Close()
+	mu.Lock()
+	defer mu.Unlock()
+	if len(ids) != 2 || ids[0] == "" || ids[0] != ids[1] {
+		t.Errorf("sent $insert_ids %q, want the same id twice", ids)
+	}
+}
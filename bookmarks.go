@@ -0,0 +1,54 @@
+package mixpanel
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+)
+
+// Bookmark is a saved report. Its ID is the bookmark_id of GetInsights.
+type Bookmark struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// ListBookmarks returns the saved reports of the project, or of the workspace
+// if Config.WorkspaceID is set. Requires a service account and
+// Config.ProjectID.
+func (c *Client) ListBookmarks() ([]Bookmark, error) {
+	return c.ListBookmarksContext(context.Background())
+}
+
+// ListBookmarksContext is ListBookmarks with a context.
+func (c *Client) ListBookmarksContext(ctx context.Context) ([]Bookmark, error) {
+	uri, err := c.config.bookmarksURL()
+	if err != nil {
+		return nil, err
+	}
+	var out struct {
+		Results []Bookmark `json:"results"`
+	}
+	if err := c.config.sendJSON(ctx, "bookmarks", http.MethodGet, uri, c.config.basicAuthHeader(""), nil, &out); err != nil {
+		return nil, err
+	}
+	return out.Results, nil
+}
+
+// bookmarksURL returns the bookmarks URL of the workspace or project.
+func (c *Config) bookmarksURL() (string, error) {
+	if err := c.checkServiceAccount(); err != nil {
+		return "", err
+	}
+	if c.ProjectID == "" {
+		return "", errors.New("mixpanel: missing project_id")
+	}
+	if err := c.checkWorkspaceID(); err != nil {
+		return "", err
+	}
+	if c.WorkspaceID != "" {
+		return c.queryEndpoint() + "/app/workspaces/" + url.PathEscape(c.WorkspaceID) + "/bookmarks?v=2", nil
+	}
+	return c.queryEndpoint() + "/app/projects/" + url.PathEscape(c.ProjectID) + "/bookmarks?v=2", nil
+}
@@ -0,0 +1,94 @@
+package mixpanel
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+)
+
+// Param sets the parameter key to value and returns req for chaining. The
+// chainable setters accumulate into Parameters, which the Run methods pass to
+// the report they execute.
+func (req *Request) Param(key string, value string) *Request {
+	if req.Parameters == nil {
+		req.Parameters = make(map[string]string)
+	}
+	req.Parameters[key] = value
+	return req
+}
+
+// Event sets the `event` parameter to name.
+func (req *Request) Event(name string) *Request { return req.Param("event", name) }
+
+// From sets the `from_date` parameter, in the format yyyy-mm-dd.
+func (req *Request) From(date string) *Request { return req.Param("from_date", date) }
+
+// To sets the `to_date` parameter, in the format yyyy-mm-dd.
+func (req *Request) To(date string) *Request { return req.Param("to_date", date) }
+
+// Range sets the `from_date` and `to_date` parameters from r.
+func (req *Request) Range(r DateRange) *Request {
+	return req.From(r.From.Format(DateFormat)).To(r.To.Format(DateFormat))
+}
+
+// Where sets the `where` parameter to expr, e.g. one built with WhereEq.
+func (req *Request) Where(expr string) *Request { return req.Param("where", expr) }
+
+// On sets the `on` parameter to expr, e.g. Property("plan").
+func (req *Request) On(expr string) *Request { return req.Param("on", expr) }
+
+// Unit sets the `unit` parameter to u.
+func (req *Request) Unit(u Unit) *Request { return req.Param("unit", string(u)) }
+
+// RunSegmentation executes Segmentation with the accumulated parameters.
+func (req *Request) RunSegmentation() (*SegmentationResult, error) {
+	return req.Segmentation(req.Parameters)
+}
+
+// RunEvents executes Events with the accumulated parameters. An `event` set
+// with Event is sent as the JSON array the endpoint expects.
+func (req *Request) RunEvents() (*EventsResult, error) {
+	params, err := eventList(req.Parameters)
+	if err != nil {
+		return nil, err
+	}
+	return req.Events(params)
+}
+
+// RunFunnel executes Funnel with the accumulated parameters.
+func (req *Request) RunFunnel() (*FunnelResult, error) {
+	return req.Funnel(req.Parameters)
+}
+
+// RunExport exports the events matching the accumulated parameters and calls
+// fn for each one, as DecodeExport. An `event` set with Event is sent as the
+// JSON array the endpoint expects.
+func (req *Request) RunExport(ctx context.Context, fn func(Event) error) error {
+	params, err := eventList(req.Parameters)
+	if err != nil {
+		return err
+	}
+	if _, err := req.GetRawData(params); err != nil {
+		return err
+	}
+	body, err := req.ExportStream(ctx)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+	return DecodeExport(body, fn)
+}
+
+// eventList returns params with a single event name in `event` encoded as a
+// JSON array.
+func eventList(params map[string]string) (map[string]string, error) {
+	event, ok := params["event"]
+	if !ok || strings.HasPrefix(event, "[") {
+		return params, nil
+	}
+	encoded, err := json.Marshal([]string{event})
+	if err != nil {
+		return nil, err
+	}
+	return withParams(params, "event", string(encoded)), nil
+}
@@ -0,0 +1,88 @@
+package mixpanel
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+type memStore struct{ cp ExportCheckpoint }
+
+func (m *memStore) Load() (ExportCheckpoint, error) { return m.cp, nil }
+func (m *memStore) Save(cp ExportCheckpoint) error  { m.cp = cp; return nil }
+
+func exportServer(t *testing.T, body string) (*Client, *[]string) {
+	t.Helper()
+	var froms []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		froms = append(froms, r.URL.Query().Get("from_date"))
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(srv.Close)
+	return NewClient(WithCredentials("key", "secret"), WithEndpoint(srv.URL, srv.URL)), &froms
+}
+
+func resume(t *testing.T, c *Client, store CheckpointStore, failAt string) ([]string, error) {
+	t.Helper()
+	req := c.NewRequest()
+	if _, err := req.GetRawData(map[string]string{"from_date": "2020-01-01", "to_date": "2020-12-31"}); err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	err := req.ExportResume(context.Background(), store, func(e Event) error {
+		if e.Name == failAt {
+			return errors.New("fail")
+		}
+		got = append(got, e.Name)
+		return nil
+	})
+	return got, err
+}
+
+func TestExportResumeOutOfOrder(t *testing.T) {
+	c, froms := exportServer(t, `{"event":"a","properties":{"time":100}}
+{"event":"c","properties":{"time":200}}
+{"event":"b","properties":{"time":50}}
+`)
+	store := &memStore{}
+	first, err := resume(t, c, store, "b")
+	if err == nil {
+		t.Fatal("expected the error returned by fn")
+	}
+	if store.cp.Offset != 2 {
+		t.Errorf("checkpoint offset = %d, want 2", store.cp.Offset)
+	}
+	second, err := resume(t, c, store, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := append(first, second...), []string{"a", "c", "b"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	if store.cp.Offset != 3 {
+		t.Errorf("checkpoint offset = %d, want 3", store.cp.Offset)
+	}
+	if (*froms)[1] != "2020-01-01" {
+		t.Errorf("resumed from_date = %q, want 2020-01-01", (*froms)[1])
+	}
+}
+
+func TestExportResumeOtherRange(t *testing.T) {
+	c, _ := exportServer(t, `{"event":"a","properties":{"time":100}}
+{"event":"b","properties":{"time":50}}
+`)
+	store := &memStore{cp: ExportCheckpoint{FromDate: "2019-01-01", ToDate: "2019-12-31", Offset: 1}}
+	got, err := resume(t, c, store, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"a", "b"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	if want := (ExportCheckpoint{FromDate: "2020-01-01", ToDate: "2020-12-31", Offset: 2}); store.cp != want {
+		t.Errorf("checkpoint = %+v, want %+v", store.cp, want)
+	}
+}
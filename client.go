@@ -0,0 +1,217 @@
+package mixpanel
+
+import (
+	"context"
+	"crypto/tls"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Client holds the configuration shared by all requests to Mixpanel. Each call
+// made through a Client uses its own Request, so a Client is safe for
+// concurrent use by multiple goroutines once created. A Request, in contrast,
+// holds per-call state and must not be shared.
+type Client struct {
+	config  Config
+	timeout time.Duration
+	// transport is the transport built by NewClient, if any, and closed by
+	// Close.
+	transport  *http.Transport
+	eventNames eventNamesCache
+}
+
+// Option configures a Client created by NewClient.
+type Option func(*Client)
+
+// WithCredentials sets the API key and secret.
+func WithCredentials(key string, secret string) Option {
+	return func(c *Client) {
+		c.config.APIKey = key
+		c.config.APISecret = secret
+	}
+}
+
+// WithHTTPClient sets the http.Client used to execute requests.
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) {
+		c.config.HTTPClient = hc
+	}
+}
+
+// WithEndpoint sets the query and raw export endpoints, e.g. EUEndpoint and
+// EURawEndpoint. An empty value keeps the default.
+func WithEndpoint(query string, raw string) Option {
+	return func(c *Client) {
+		c.config.QueryEndpoint = query
+		c.config.RawEndpoint = raw
+	}
+}
+
+// WithIngestEndpoint sets the ingestion endpoint, e.g. EUIngestEndpoint.
+func WithIngestEndpoint(ingest string) Option {
+	return func(c *Client) {
+		c.config.IngestEndpoint = ingest
+	}
+}
+
+// WithQueryAPI sends queries to the api.mixpanel.com query API, or its EU
+// counterpart if eu is set. It requires WithServiceAccount.
+func WithQueryAPI(eu bool) Option {
+	return func(c *Client) {
+		c.config.QueryEndpoint = QueryAPIEndpoint
+		if eu {
+			c.config.QueryEndpoint = EUQueryAPIEndpoint
+		}
+	}
+}
+
+// WithToken sets the project token used for ingestion.
+func WithToken(token string) Option {
+	return func(c *Client) {
+		c.config.Token = token
+	}
+}
+
+// WithServiceAccount sets the service account credentials and project id.
+func WithServiceAccount(user string, secret string, projectID string) Option {
+	return func(c *Client) {
+		c.config.ServiceAccountUser = user
+		c.config.ServiceAccountSecret = secret
+		c.config.ProjectID = projectID
+	}
+}
+
+// WithProxy sets the proxy used by the Client's transport. It has no effect
+// if WithHTTPClient is also given.
+func WithProxy(proxy func(*http.Request) (*url.URL, error)) Option {
+	return func(c *Client) {
+		c.config.Proxy = proxy
+	}
+}
+
+// WithTLSConfig sets the TLS configuration of the Client's transport, e.g.
+// to trust a custom CA bundle. It has no effect if WithHTTPClient is also
+// given.
+func WithTLSConfig(cfg *tls.Config) Option {
+	return func(c *Client) {
+		c.config.TLSConfig = cfg
+	}
+}
+
+// WithWorkspaceID scopes the Client's queries to the workspace id.
+func WithWorkspaceID(id string) Option {
+	return func(c *Client) {
+		c.config.WorkspaceID = id
+	}
+}
+
+// WithOAuthToken sets the OAuth token used by the data deletion API.
+func WithOAuthToken(token string) Option {
+	return func(c *Client) {
+		c.config.OAuthToken = token
+	}
+}
+
+// WithTimeout sets the timeout of the http.Client used by the Client. When
+// combined with WithHTTPClient, the given client is copied rather than modified.
+func WithTimeout(d time.Duration) Option {
+	return func(c *Client) {
+		c.timeout = d
+	}
+}
+
+// WithRateLimit limits the Client to perSecond requests per second with
+// bursts of up to burst requests.
+func WithRateLimit(perSecond float64, burst int) Option {
+	return func(c *Client) {
+		c.config.RateLimiter = NewRateLimiter(perSecond, burst)
+	}
+}
+
+// WithMaxConcurrency bounds the number of requests the Client has in flight
+// at once.
+func WithMaxConcurrency(n int) Option {
+	return func(c *Client) {
+		c.config.MaxConcurrency = n
+	}
+}
+
+// WithConfig replaces the whole Config of the Client.
+func WithConfig(cfg Config) Option {
+	return func(c *Client) {
+		c.config = cfg
+	}
+}
+
+// NewClient returns a Client configured by opts.
+func NewClient(opts ...Option) *Client {
+	c := new(Client)
+	for _, opt := range opts {
+		opt(c)
+	}
+	if c.config.HTTPClient == nil && (c.config.Proxy != nil || c.config.TLSConfig != nil) {
+		c.config.HTTPClient = c.config.newHTTPClient()
+		c.transport = c.config.HTTPClient.Transport.(*http.Transport)
+	}
+	if c.config.MaxConcurrency > 0 {
+		c.config.sem = make(chan struct{}, c.config.MaxConcurrency)
+	}
+	if c.timeout > 0 {
+		hc := new(http.Client)
+		if c.config.HTTPClient != nil {
+			*hc = *c.config.HTTPClient
+		}
+		hc.Timeout = c.timeout
+		c.config.HTTPClient = hc
+	}
+	return c
+}
+
+// Close closes the idle connections of the transport the Client built for
+// WithProxy or WithTLSConfig. Clients given by WithHTTPClient or WithConfig,
+// and http.DefaultClient, are left untouched. The Client remains usable.
+func (c *Client) Close() {
+	if c.transport != nil {
+		c.transport.CloseIdleConnections()
+	}
+}
+
+// Config returns a copy of the Client's configuration.
+func (c *Client) Config() Config {
+	return c.config
+}
+
+// NewRequest returns a fresh Request using the Client's configuration.
+func (c *Client) NewRequest() *Request {
+	return NewRequestWithConfig(c.config)
+}
+
+// Query executes a query against endpoint and method with params on a fresh
+// Request and returns the raw JSON response.
+func (c *Client) Query(ctx context.Context, endpoint string, method string, params map[string]string) ([]byte, error) {
+	req := c.NewRequest()
+	req.CreateRequest(false, endpoint, method, 0, params)
+	return req.DoContext(ctx, false)
+}
+
+// GetEvents executes an events query with params and returns the raw JSON
+// response. Params are validated as in Request.GetEvents.
+func (c *Client) GetEvents(ctx context.Context, params map[string]string) ([]byte, error) {
+	req := c.NewRequest()
+	if _, err := req.GetEvents(params); err != nil {
+		return nil, err
+	}
+	return req.DoContext(ctx, false)
+}
+
+// Export opens a raw export with params on a fresh Request. The caller must
+// close the returned reader.
+func (c *Client) Export(ctx context.Context, params map[string]string) (io.ReadCloser, error) {
+	req := c.NewRequest()
+	if _, err := req.GetRawData(params); err != nil {
+		return nil, err
+	}
+	return req.ExportStream(ctx)
+}
@@ -0,0 +1,62 @@
+package mixpanel
+
+import (
+	"context"
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+)
+
+func TestProxyTransportSharedByRequests(t *testing.T) {
+	proxy := http.ProxyURL(&url.URL{Scheme: "http", Host: "proxy.example:3128"})
+	c := NewClient(WithCredentials("key", "secret"), WithProxy(proxy), WithTLSConfig(&tls.Config{ServerName: "mixpanel.com"}))
+	first, second := c.NewRequest(), c.NewRequest()
+	if first.httpClient() != second.httpClient() {
+		t.Fatal("requests of one Client use different http.Clients")
+	}
+	tr, ok := first.httpClient().Transport.(*http.Transport)
+	if !ok || tr.TLSClientConfig.ServerName != "mixpanel.com" || tr.Proxy == nil {
+		t.Fatalf("transport not configured from Proxy and TLSConfig: %#v", first.httpClient().Transport)
+	}
+	c.Close()
+}
+
+func TestHTTPClientTakesPrecedence(t *testing.T) {
+	hc := &http.Client{}
+	c := NewClient(WithHTTPClient(hc), WithProxy(http.ProxyFromEnvironment))
+	if c.NewRequest().httpClient() != hc {
+		t.Error("WithProxy replaced the client given by WithHTTPClient")
+	}
+}
+
+// TestClientConcurrentUse is meant to be run with -race.
+func TestClientConcurrentUse(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !VerifySignature(queryParams(t, "?"+r.URL.RawQuery), "secret", r.URL.Query().Get("sig")) {
+			t.Errorf("bad signature for %s", r.URL)
+		}
+		w.Write([]byte(`{"data":{}}`))
+	}))
+	defer srv.Close()
+	c := NewClient(WithCredentials("key", "secret"), WithEndpoint(srv.URL, srv.URL))
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			var err error
+			if i%2 == 0 {
+				_, err = c.GetEvents(context.Background(), map[string]string{"event": `["a b"]`, "unit": "day", "interval": "7"})
+			} else {
+				_, err = c.NewRequest().FetchSegmentation(map[string]string{"event": "a", "from_date": "2024-01-01", "to_date": "2024-01-31"})
+			}
+			if err != nil {
+				t.Error(err)
+			}
+		}(i)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,53 @@
+package mixpanel
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+)
+
+// Cohort is a cohort as returned by the cohorts list endpoint.
+type Cohort struct {
+	ID          int    `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Count       int    `json:"count"`
+	IsVisible   int    `json:"is_visible"`
+	ProjectID   int    `json:"project_id"`
+	Created     string `json:"created"`
+}
+
+// GetCohortsList gets the cohorts defined in the project. Their ids can be
+// used in the `filter_by_cohort` parameter of GetEngage.
+func (req *Request) GetCohortsList() (string, error) {
+	return req.createChecked(false, "cohorts", "list", nil)
+}
+
+// ListCohorts executes GetCohortsList and decodes the result. The endpoint
+// only accepts POST.
+func (req *Request) ListCohorts() ([]Cohort, error) {
+	if _, err := req.GetCohortsList(); err != nil {
+		return nil, err
+	}
+	body, err := req.DoPost(false)
+	if err != nil {
+		return nil, err
+	}
+	var cohorts []Cohort
+	if err := decodeJSON(body, &cohorts); err != nil {
+		return nil, err
+	}
+	return cohorts, nil
+}
+
+// ExportCohort calls fn for every profile in the cohort cohortID, paging
+// through the engage endpoint with `filter_by_cohort`. Iteration stops at the
+// first error returned by fn.
+func (c *Client) ExportCohort(ctx context.Context, cohortID string, fn func(Profile) error) error {
+	id, err := strconv.ParseInt(cohortID, 10, 64)
+	if err != nil {
+		return fmt.Errorf("mixpanel: cohort id %q is not numeric", cohortID)
+	}
+	params := map[string]string{"filter_by_cohort": fmt.Sprintf(`{"id":%d}`, id)}
+	return c.NewRequest().EachEngageProfile(ctx, params, fn)
+}
@@ -0,0 +1,84 @@
+package mixpanel
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ExportRangeConcurrent exports every day of r as a separate raw export, using
+// up to workers concurrent downloads, and calls fn for each event. The
+// current Parameters of req, such as `event` or `where`, are applied to every
+// day. fn is never called concurrently, but events of different days may
+// interleave. The first error cancels the remaining downloads; all errors are
+// returned combined.
+func (req *Request) ExportRangeConcurrent(ctx context.Context, r DateRange, workers int, fn func(Event) error) error {
+	if workers < 1 {
+		workers = 1
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	days := make(chan DateRange)
+	go func() {
+		defer close(days)
+		to := r.To.Format(DateFormat)
+		for day := r.From; day.Format(DateFormat) <= to; day = day.AddDate(0, 0, 1) {
+			select {
+			case days <- DateRange{From: day, To: day}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	var (
+		mu   sync.Mutex // serializes fn and guards errs
+		errs []error
+		wg   sync.WaitGroup
+	)
+	fail := func(err error) {
+		mu.Lock()
+		errs = append(errs, err)
+		mu.Unlock()
+		cancel()
+	}
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for day := range days {
+				err := req.exportDay(ctx, day, &mu, fn)
+				// Once ctx is done, errors are only the echo of the cancellation.
+				if err != nil && ctx.Err() == nil {
+					fail(fmt.Errorf("mixpanel: export %s: %w", day.From.Format(DateFormat), err))
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	if len(errs) == 0 {
+		return ctx.Err()
+	}
+	return errors.Join(errs...)
+}
+
+// exportDay streams a single day of req's export to fn, holding mu around each call.
+func (req *Request) exportDay(ctx context.Context, day DateRange, mu *sync.Mutex, fn func(Event) error) error {
+	sub := NewRequestWithConfig(req.Config)
+	if _, err := sub.GetRawData(day.Apply(withParams(req.Parameters))); err != nil {
+		return err
+	}
+	body, err := sub.ExportStream(ctx)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+	return DecodeExport(body, func(e Event) error {
+		mu.Lock()
+		defer mu.Unlock()
+		return fn(e)
+	})
+}
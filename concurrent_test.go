@@ -0,0 +1,26 @@
+package mixpanel
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExportRangeConcurrentCallbackError(t *testing.T) {
+	c, _ := exportServer(t, `{"event":"a","properties":{"time":100}}
+`)
+	req := c.NewRequest()
+	r := DateRange{From: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC), To: time.Date(2020, 1, 10, 0, 0, 0, 0, time.UTC)}
+	err := req.ExportRangeConcurrent(context.Background(), r, 3, func(Event) error {
+		return fmt.Errorf("write: %w", context.Canceled)
+	})
+	if err == nil || !strings.Contains(err.Error(), "write") {
+		t.Fatalf("err = %v, want the error returned by fn", err)
+	}
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("err = %v, want it to wrap context.Canceled", err)
+	}
+}
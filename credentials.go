@@ -0,0 +1,62 @@
+package mixpanel
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// Environment variables read by LoadFromEnv.
+const (
+	EnvAPIKey       = "MIXPANEL_API_KEY"
+	EnvAPISecret    = "MIXPANEL_API_SECRET"
+	EnvProjectToken = "MIXPANEL_PROJECT_TOKEN"
+	EnvProjectID    = "MIXPANEL_PROJECT_ID"
+)
+
+// ReadCredentials reads the API key and secret from key and secret, e.g. an
+// in-memory buffer or a file from an embedded FS.
+func (c *Config) ReadCredentials(key io.Reader, secret io.Reader) error {
+	k, err := ReaderContents(key)
+	if err != nil {
+		return fmt.Errorf("mixpanel: error reading API key: %v", err)
+	}
+	s, err := ReaderContents(secret)
+	if err != nil {
+		return fmt.Errorf("mixpanel: error reading API secret: %v", err)
+	}
+	c.APIKey = k
+	c.APISecret = s
+	return nil
+}
+
+// LoadFromEnv fills the credentials of c from MIXPANEL_API_KEY and
+// MIXPANEL_API_SECRET, and the token and project id from the optional
+// MIXPANEL_PROJECT_TOKEN and MIXPANEL_PROJECT_ID. It returns an error naming
+// every required variable that is unset; c is only modified on success.
+func (c *Config) LoadFromEnv() error {
+	key := strings.TrimSpace(os.Getenv(EnvAPIKey))
+	secret := strings.TrimSpace(os.Getenv(EnvAPISecret))
+
+	var missing []string
+	if key == "" {
+		missing = append(missing, EnvAPIKey)
+	}
+	if secret == "" {
+		missing = append(missing, EnvAPISecret)
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("mixpanel: missing environment variables: %s", strings.Join(missing, ", "))
+	}
+
+	c.APIKey = key
+	c.APISecret = secret
+	if token := strings.TrimSpace(os.Getenv(EnvProjectToken)); token != "" {
+		c.Token = token
+	}
+	if id := strings.TrimSpace(os.Getenv(EnvProjectID)); id != "" {
+		c.ProjectID = id
+	}
+	return nil
+}
@@ -0,0 +1,34 @@
+package mixpanel
+
+import "time"
+
+// DateFormat is the layout of the `from_date` and `to_date` parameters.
+const DateFormat = "2006-01-02"
+
+// DateRange is an inclusive range of days for the `from_date` and `to_date`
+// parameters. Only the date part of From and To is used.
+type DateRange struct {
+	From time.Time
+	To   time.Time
+}
+
+// LastNDays returns the range of the n days ending today, inclusive, so
+// LastNDays(1) is just today.
+func LastNDays(n int) DateRange {
+	if n < 1 {
+		n = 1
+	}
+	now := time.Now()
+	return DateRange{From: now.AddDate(0, 0, -(n - 1)), To: now}
+}
+
+// Apply sets `from_date` and `to_date` in params and returns params. A nil
+// params is allocated.
+func (r DateRange) Apply(params map[string]string) map[string]string {
+	if params == nil {
+		params = make(map[string]string)
+	}
+	params["from_date"] = r.From.Format(DateFormat)
+	params["to_date"] = r.To.Format(DateFormat)
+	return params
+}
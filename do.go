@@ -0,0 +1,333 @@
+package mixpanel
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Do performs the request described by req and returns the raw response body.
+// The request must already have been prepared by CreateRequest or one of its wrappers.
+func (req *Request) Do(rawflag bool) ([]byte, error) {
+	return req.DoContext(context.Background(), rawflag)
+}
+
+// DoContext is Do with a context. Cancelling ctx aborts the request, including
+// a body that is still being read.
+func (req *Request) DoContext(ctx context.Context, rawflag bool) ([]byte, error) {
+	u, err := req.URL(rawflag)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := req.sendURL(ctx, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	return req.readResponse(ctx, resp, rawflag)
+}
+
+// readLimited reads r up to MaxResponseBytes. Unless MaxResponseBytes is set,
+// raw exports are read without limit.
+func (c *Config) readLimited(r io.Reader, raw bool) ([]byte, error) {
+	limit := c.MaxResponseBytes
+	if limit == 0 && !raw {
+		limit = DefaultMaxResponseBytes
+	}
+	if limit <= 0 {
+		return ioutil.ReadAll(r)
+	}
+	body, err := ioutil.ReadAll(io.LimitReader(r, limit+1))
+	if err == nil && int64(len(body)) > limit {
+		return nil, ErrResponseTooLarge
+	}
+	return body, err
+}
+
+// sendURL sends the prepared request u like send, switching to a POST with
+// the same signed parameters as a form body when the query is longer than
+// Config.MaxQueryLength.
+func (req *Request) sendURL(ctx context.Context, u *url.URL, header http.Header) (*http.Response, error) {
+	if len(u.RawQuery) <= req.maxQueryLength() {
+		return req.send(ctx, u.String(), header)
+	}
+	form := []byte(u.RawQuery)
+	post := *u
+	post.RawQuery = ""
+	header = header.Clone()
+	if header == nil {
+		header = make(http.Header)
+	}
+	for key, values := range formHeader() {
+		header[key] = values
+	}
+	return req.Config.sendBody(ctx, requestName(req.Endpoint, req.Method), http.MethodPost, post.String(), form, req.requestHeader(header))
+}
+
+// DoJSON performs the request like Do and unmarshals the JSON response into out,
+// which must be a pointer.
+func (req *Request) DoJSON(rawflag bool, out interface{}) error {
+	return req.DoJSONContext(context.Background(), rawflag, out)
+}
+
+// DoJSONContext is DoJSON with a context.
+func (req *Request) DoJSONContext(ctx context.Context, rawflag bool, out interface{}) error {
+	body, err := req.DoContext(ctx, rawflag)
+	if err != nil {
+		return err
+	}
+	return decodeJSON(body, out)
+}
+
+// decodeJSON unmarshals body into out, reporting the start of the body on failure.
+func decodeJSON(body []byte, out interface{}) error {
+	if err := json.Unmarshal(body, out); err != nil {
+		snippet := body
+		if len(snippet) > 64 {
+			snippet = snippet[:64]
+		}
+		return fmt.Errorf("mixpanel: invalid JSON response %q: %v", snippet, err)
+	}
+	return nil
+}
+
+// Execute performs a GET against uri and returns the raw response body. Any
+// non-2xx response is returned as an error.
+func (req *Request) Execute(uri string) ([]byte, error) {
+	return req.ExecuteContext(context.Background(), uri)
+}
+
+// ExecuteContext is Execute with a context. Responses with status 429 or 5xx
+// are retried up to MaxRetries times. Error responses are returned as *APIError.
+func (req *Request) ExecuteContext(ctx context.Context, uri string) ([]byte, error) {
+	resp, err := req.send(ctx, uri, nil)
+	if err != nil {
+		return nil, err
+	}
+	return req.readBody(ctx, resp)
+}
+
+// readBody reads and closes the body of a successful response, returning an
+// *APIError if it is a Mixpanel error object and ErrResponseTooLarge if it is
+// longer than MaxResponseBytes.
+func (c *Config) readBody(ctx context.Context, resp *http.Response) ([]byte, error) {
+	return c.readResponse(ctx, resp, false)
+}
+
+// readResponse is readBody, with the limit of raw exports if raw is set.
+func (c *Config) readResponse(ctx context.Context, resp *http.Response, raw bool) ([]byte, error) {
+	defer resp.Body.Close()
+
+	body, err := c.readLimited(resp.Body, raw)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, err
+	}
+	if err := checkAPIError(resp.StatusCode, body); err != nil {
+		return nil, err
+	}
+	return body, nil
+}
+
+// DoPost performs the prepared request as a POST, sending the signed
+// parameters as a form-encoded body, and returns the raw response body.
+func (req *Request) DoPost(rawflag bool) ([]byte, error) {
+	return req.DoPostContext(context.Background(), rawflag)
+}
+
+// DoPostContext is DoPost with a context.
+func (req *Request) DoPostContext(ctx context.Context, rawflag bool) ([]byte, error) {
+	u, err := req.URL(rawflag)
+	if err != nil {
+		return nil, err
+	}
+	form := []byte(u.RawQuery)
+	u.RawQuery = ""
+	resp, err := req.Config.sendBody(ctx, requestName(req.Endpoint, req.Method), http.MethodPost, u.String(), form, req.requestHeader(formHeader()))
+	if err != nil {
+		return nil, err
+	}
+	return req.readResponse(ctx, resp, rawflag)
+}
+
+// sendJSON sends in, if non-nil, JSON-encoded to uri and decodes the JSON
+// response into out, if non-nil. name labels the request as in sendBody.
+func (c *Config) sendJSON(ctx context.Context, name string, method string, uri string, header http.Header, in interface{}, out interface{}) error {
+	var payload []byte
+	if in != nil {
+		var err error
+		if payload, err = json.Marshal(in); err != nil {
+			return err
+		}
+		header = header.Clone()
+		if header == nil {
+			header = make(http.Header)
+		}
+		header.Set("Content-Type", "application/json")
+	}
+	resp, err := c.sendBody(ctx, name, method, uri, payload, header)
+	if err != nil {
+		return err
+	}
+	body, err := c.readBody(ctx, resp)
+	if err != nil || out == nil {
+		return err
+	}
+	return decodeJSON(body, out)
+}
+
+// formHeader returns the headers for a form-encoded request body.
+func formHeader() http.Header {
+	return http.Header{"Content-Type": {"application/x-www-form-urlencoded"}}
+}
+
+// requestHeader returns a copy of header with the Accept header for the
+// request's format and, when one is configured, the service account
+// Authorization header added.
+func (req *Request) requestHeader(header http.Header) http.Header {
+	out := make(http.Header, len(header)+2)
+	if req.usesServiceAccount() {
+		out = req.basicAuthHeader("")
+	}
+	out.Set("Accept", req.accept())
+	for key, values := range header {
+		out[key] = values
+	}
+	return out
+}
+
+// accept returns the media types the request's response may have: JSON, CSV
+// when the format is FormatCSV, or newline-delimited JSON for exports.
+func (req *Request) accept() string {
+	switch {
+	case req.Endpoint == "export":
+		return "application/x-ndjson, application/json"
+	case req.format() == FormatCSV:
+		return "text/csv"
+	}
+	return "application/json"
+}
+
+// send performs a GET against uri with the extra headers in header, retrying
+// 429 and 5xx responses, and returns the first successful response with its
+// body still open. Non-2xx responses are returned as an *APIError.
+func (req *Request) send(ctx context.Context, uri string, header http.Header) (*http.Response, error) {
+	return req.Config.sendBody(ctx, requestName(req.Endpoint, req.Method), http.MethodGet, uri, nil, req.requestHeader(header))
+}
+
+// newHTTPRequest returns a request for uri with body, the User-Agent, an
+// Accept header for JSON and the headers in header, which take precedence.
+func (c *Config) newHTTPRequest(ctx context.Context, method string, uri string, body []byte, header http.Header) (*http.Request, error) {
+	var rbody io.Reader
+	if body != nil {
+		rbody = bytes.NewReader(body)
+	}
+	hreq, err := http.NewRequestWithContext(ctx, method, uri, rbody)
+	if err != nil {
+		return nil, err
+	}
+	hreq.Header.Set("User-Agent", c.userAgent())
+	hreq.Header.Set("Accept", "application/json")
+	for key, values := range header {
+		hreq.Header[key] = values
+	}
+	return hreq, nil
+}
+
+// HTTPRequest returns the prepared request as an *http.Request, ready to be
+// sent with any http.Client or RoundTripper, without executing it. Endpoints
+// that only accept POST, such as jql, and queries longer than MaxQueryLength
+// get their parameters as a form body.
+// The request is not retried or rate limited.
+func (req *Request) HTTPRequest(ctx context.Context, rawflag bool) (*http.Request, error) {
+	u, err := req.URL(rawflag)
+	if err != nil {
+		return nil, err
+	}
+	if !postEndpoints[requestName(req.Endpoint, req.Method)] && len(u.RawQuery) <= req.maxQueryLength() {
+		return req.newHTTPRequest(ctx, http.MethodGet, u.String(), nil, req.requestHeader(nil))
+	}
+	form := []byte(u.RawQuery)
+	u.RawQuery = ""
+	return req.newHTTPRequest(ctx, http.MethodPost, u.String(), form, req.requestHeader(formHeader()))
+}
+
+// sendBody is send with an arbitrary method, request body and headers. The
+// body is resent on every attempt. OnMetrics, if set, is called once the
+// call completes, with name, a stable name of the API called such as
+// "funnels/list" or "import", as the endpoint.
+func (c *Config) sendBody(ctx context.Context, name string, method string, uri string, body []byte, header http.Header) (*http.Response, error) {
+	if c.OnMetrics == nil {
+		return c.sendAttempts(ctx, method, uri, body, header, nil)
+	}
+	m := RequestMetrics{Method: method, Endpoint: name}
+	start := time.Now()
+	resp, err := c.sendAttempts(ctx, method, uri, body, header, &m)
+	m.Duration = time.Since(start)
+	m.Err = err
+	c.OnMetrics(m)
+	return resp, err
+}
+
+// sendAttempts implements sendBody, recording the status and retries into m
+// if it is non-nil.
+func (c *Config) sendAttempts(ctx context.Context, method string, uri string, body []byte, header http.Header, m *RequestMetrics) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		hreq, err := c.newHTTPRequest(ctx, method, uri, body, header)
+		if err != nil {
+			return nil, err
+		}
+		if m != nil {
+			m.Retries = attempt
+		}
+		if c.DryRun {
+			return nil, &DryRunError{Request: hreq}
+		}
+		if c.RateLimiter != nil {
+			if err := c.RateLimiter.Wait(ctx); err != nil {
+				return nil, err
+			}
+		}
+		release, err := c.acquire(ctx)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := c.roundTrip(hreq, attempt)
+		if err != nil {
+			release()
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			return nil, err
+		}
+		if m != nil {
+			m.StatusCode = resp.StatusCode
+		}
+		if resp.StatusCode >= 200 && resp.StatusCode <= 299 {
+			resp.Body = releaseBody{resp.Body, release}
+			return resp, nil
+		}
+
+		msg, _ := c.readLimited(resp.Body, false)
+		resp.Body.Close()
+		release()
+		if attempt >= c.MaxRetries || !retryable(resp.StatusCode) {
+			return nil, checkAPIError(resp.StatusCode, msg)
+		}
+
+		delay, ok := retryAfter(resp.Header, time.Now())
+		if !ok {
+			delay = c.backoff(attempt)
+		}
+		if err := sleepContext(ctx, delay); err != nil {
+			return nil, err
+		}
+	}
+}
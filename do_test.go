@@ -0,0 +1,49 @@
+package mixpanel
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func bodyServer(t *testing.T, body []byte) *Client {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(body)
+	}))
+	t.Cleanup(srv.Close)
+	return NewClient(WithCredentials("key", "secret"), WithEndpoint(srv.URL, srv.URL))
+}
+
+func TestDoRawExportUnlimitedByDefault(t *testing.T) {
+	line := []byte(`{"event":"a","properties":{}}` + "\n")
+	body := bytes.Repeat(line, DefaultMaxResponseBytes/len(line)+1)
+	req := bodyServer(t, body).NewRequest()
+	if _, err := req.GetRawData(map[string]string{"from_date": "2020-01-01", "to_date": "2020-01-02"}); err != nil {
+		t.Fatal(err)
+	}
+	got, err := req.Do(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != len(body) {
+		t.Errorf("read %d bytes, want %d", len(got), len(body))
+	}
+}
+
+func TestMaxResponseBytes(t *testing.T) {
+	for _, raw := range []bool{false, true} {
+		c := bodyServer(t, bytes.Repeat([]byte("x"), 11))
+		cfg := c.Config()
+		cfg.MaxResponseBytes = 10
+		req := NewRequestWithConfig(cfg)
+		if _, err := req.GetEvents(map[string]string{"event": `["a"]`}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := req.Do(raw); !errors.Is(err, ErrResponseTooLarge) {
+			t.Errorf("Do(%v) error = %v, want ErrResponseTooLarge", raw, err)
+		}
+	}
+}
@@ -0,0 +1,190 @@
+package mixpanel
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// Profile is a single user profile as returned by the engage endpoint.
+type Profile struct {
+	DistinctID string                 `json:"$distinct_id"`
+	Properties map[string]interface{} `json:"$properties"`
+}
+
+// PeopleResult is one page of engage results. Pass SessionID and Page+1 back
+// as `session_id` and `page` to fetch the next page.
+type PeopleResult struct {
+	Page      int       `json:"page"`
+	PageSize  int       `json:"page_size"`
+	SessionID string    `json:"session_id"`
+	Total     int       `json:"total"`
+	Results   []Profile `json:"results"`
+}
+
+// People executes an engage query with params and decodes one page of results.
+func (req *Request) People(ctx context.Context, params map[string]string) (*PeopleResult, error) {
+	if _, err := req.GetEngage(params); err != nil {
+		return nil, err
+	}
+	var page PeopleResult
+	if err := req.DoJSONContext(ctx, false, &page); err != nil {
+		return nil, err
+	}
+	return &page, nil
+}
+
+// EachEngageProfile queries the engage endpoint with params and calls fn for
+// every profile, advancing through pages until no more results are returned.
+// Iteration stops at the first error returned by fn.
+func (req *Request) EachEngageProfile(ctx context.Context, params map[string]string, fn func(Profile) error) error {
+	p := make(map[string]string, len(params)+2)
+	for key, value := range params {
+		p[key] = value
+	}
+	delete(p, "session_id")
+	delete(p, "page")
+
+	for {
+		page, err := req.People(ctx, p)
+		if err != nil {
+			return err
+		}
+		for _, profile := range page.Results {
+			if err := fn(profile); err != nil {
+				return err
+			}
+		}
+		if len(page.Results) == 0 || (page.PageSize > 0 && len(page.Results) < page.PageSize) {
+			return nil
+		}
+		p["session_id"] = page.SessionID
+		p["page"] = strconv.Itoa(page.Page + 1)
+	}
+}
+
+// errMissingDistinctID is returned by profile updates made without a distinct_id.
+var errMissingDistinctID = errors.New("mixpanel: missing distinct_id")
+
+// EngageContext applies the profile operation op, such as "$set" or "$unset",
+// with value to the profile of distinctID.
+func (c *Client) EngageContext(ctx context.Context, distinctID string, op string, value interface{}) error {
+	if c.config.Token == "" {
+		return errMissingToken
+	}
+	if distinctID == "" {
+		return errMissingDistinctID
+	}
+	payload, err := json.Marshal(map[string]interface{}{
+		"$token":       c.config.Token,
+		"$distinct_id": distinctID,
+		op:             value,
+	})
+	if err != nil {
+		return err
+	}
+	return c.config.postIngest(ctx, "engage", payload)
+}
+
+// EngageSet sets properties on the profile, overwriting existing values.
+func (c *Client) EngageSet(distinctID string, properties map[string]interface{}) error {
+	return c.EngageContext(context.Background(), distinctID, "$set", properties)
+}
+
+// EngageSetOnce sets properties on the profile only where they are not already set.
+func (c *Client) EngageSetOnce(distinctID string, properties map[string]interface{}) error {
+	return c.EngageContext(context.Background(), distinctID, "$set_once", properties)
+}
+
+// EngageAdd increments numeric properties on the profile by the given amounts.
+func (c *Client) EngageAdd(distinctID string, increments map[string]float64) error {
+	return c.EngageContext(context.Background(), distinctID, "$add", increments)
+}
+
+// EngageAppend appends values to list properties on the profile.
+func (c *Client) EngageAppend(distinctID string, values map[string]interface{}) error {
+	return c.EngageContext(context.Background(), distinctID, "$append", values)
+}
+
+// EngageUnion merges values into list properties on the profile, ignoring
+// values already present.
+func (c *Client) EngageUnion(distinctID string, values map[string][]interface{}) error {
+	return c.EngageContext(context.Background(), distinctID, "$union", values)
+}
+
+// EngageUnset removes the named properties from the profile.
+func (c *Client) EngageUnset(distinctID string, names []string) error {
+	return c.EngageContext(context.Background(), distinctID, "$unset", names)
+}
+
+// EngageRemove removes values from list properties on the profile.
+func (c *Client) EngageRemove(distinctID string, values map[string]interface{}) error {
+	return c.EngageContext(context.Background(), distinctID, "$remove", values)
+}
+
+// EngageDelete deletes the profile.
+func (c *Client) EngageDelete(distinctID string) error {
+	return c.EngageContext(context.Background(), distinctID, "$delete", "")
+}
+
+// EngageBatchSize is the maximum number of profile operations sent in a single
+// /engage request.
+const EngageBatchSize = 2000
+
+// EngageOp is a single profile operation for EngageBatch: Op, such as "$set",
+// applied with Value to the profile DistinctID, as in EngageContext.
+type EngageOp struct {
+	DistinctID string
+	Op         string
+	Value      interface{}
+}
+
+// EngageBatch applies ops in requests of up to EngageBatchSize operations.
+// All batches are attempted; the error joins those of the batches that failed.
+func (c *Client) EngageBatch(ops []EngageOp) error {
+	return c.EngageBatchContext(context.Background(), ops)
+}
+
+// EngageBatchContext is EngageBatch with a context.
+func (c *Client) EngageBatchContext(ctx context.Context, ops []EngageOp) error {
+	if c.config.Token == "" {
+		return errMissingToken
+	}
+	for _, op := range ops {
+		if op.DistinctID == "" {
+			return errMissingDistinctID
+		}
+		if op.Op == "" {
+			return errors.New("mixpanel: missing engage operation")
+		}
+	}
+	var errs []error
+	for start := 0; start < len(ops); start += EngageBatchSize {
+		if err := ctx.Err(); err != nil {
+			errs = append(errs, err)
+			break
+		}
+		end := start + EngageBatchSize
+		if end > len(ops) {
+			end = len(ops)
+		}
+		batch := make([]map[string]interface{}, 0, end-start)
+		for _, op := range ops[start:end] {
+			batch = append(batch, map[string]interface{}{
+				"$token":       c.config.Token,
+				"$distinct_id": op.DistinctID,
+				op.Op:          op.Value,
+			})
+		}
+		payload, err := json.Marshal(batch)
+		if err == nil {
+			err = c.config.postIngest(ctx, "engage", payload)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("mixpanel: engage batch %d-%d: %w", start, end-1, err))
+		}
+	}
+	return errors.Join(errs...)
+}
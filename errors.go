@@ -0,0 +1,68 @@
+package mixpanel
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// APIError is returned when Mixpanel responds with a non-2xx status or with an
+// error body such as {"request": "/api/2.0/events/", "error": "invalid signature"}.
+type APIError struct {
+	// StatusCode is the HTTP status of the response.
+	StatusCode int
+	// Message is the `error` field of the response, or the raw body if it had none.
+	Message string
+	// Request is the `request` field of the response, identifying the API called.
+	Request string
+	// Body is the raw response body.
+	Body []byte
+}
+
+func (e *APIError) Error() string {
+	if e.Request != "" {
+		return fmt.Sprintf("mixpanel: %s %s: %s", http.StatusText(e.StatusCode), e.Request, e.Message)
+	}
+	return fmt.Sprintf("mixpanel: %s: %s", http.StatusText(e.StatusCode), e.Message)
+}
+
+// ErrResponseTooLarge is returned when a buffered response body is longer than
+// Config.MaxResponseBytes.
+var ErrResponseTooLarge = errors.New("mixpanel: response body exceeds MaxResponseBytes")
+
+// DryRunError is returned instead of sending a request when Config.DryRun is
+// set. Request is the request that would have been sent, including its
+// headers and body.
+type DryRunError struct {
+	Request *http.Request
+}
+
+func (e *DryRunError) Error() string {
+	return fmt.Sprintf("mixpanel: dry run: %s %s", e.Request.Method, e.Request.URL)
+}
+
+// errorBody is the shape of Mixpanel's error responses.
+type errorBody struct {
+	Request string `json:"request"`
+	Error   string `json:"error"`
+}
+
+// checkAPIError returns an *APIError if status is not 2xx or body is a
+// Mixpanel error object, and nil otherwise.
+func checkAPIError(status int, body []byte) error {
+	var eb errorBody
+	if trimmed := bytes.TrimSpace(body); len(trimmed) > 0 && trimmed[0] == '{' {
+		json.Unmarshal(trimmed, &eb)
+	}
+	if status >= 200 && status <= 299 && eb.Error == "" {
+		return nil
+	}
+	msg := eb.Error
+	if msg == "" {
+		msg = strings.TrimSpace(string(body))
+	}
+	return &APIError{StatusCode: status, Message: msg, Request: eb.Request, Body: body}
+}
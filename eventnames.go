@@ -0,0 +1,78 @@
+package mixpanel
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// eventNamesCache holds the result of Client.EventNames.
+type eventNamesCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	names   []string
+	fetched time.Time
+	gen     int // incremented by InvalidateEventNames, so in-flight fetches are not cached
+}
+
+// WithEventNamesTTL caches the result of EventNames for ttl. Zero, the
+// default, disables the cache.
+func WithEventNamesTTL(ttl time.Duration) Option {
+	return func(c *Client) {
+		c.eventNames.ttl = ttl
+	}
+}
+
+// EventNames returns the names of the project's events, most common first.
+// With WithEventNamesTTL, results younger than the TTL are served from memory.
+// The returned slice must not be modified.
+func (c *Client) EventNames(ctx context.Context) ([]string, error) {
+	cache := &c.eventNames
+	if cache.ttl <= 0 {
+		return c.fetchEventNames(ctx)
+	}
+	now := c.config.now()
+	cache.mu.Lock()
+	if cache.names != nil && now.Sub(cache.fetched) < cache.ttl {
+		names := cache.names
+		cache.mu.Unlock()
+		return names, nil
+	}
+	gen := cache.gen
+	cache.mu.Unlock()
+
+	// The lock is not held while fetching, so concurrent misses each fetch.
+	names, err := c.fetchEventNames(ctx)
+	if err != nil {
+		return nil, err
+	}
+	cache.mu.Lock()
+	if cache.gen == gen {
+		cache.names = names
+		cache.fetched = now
+	}
+	cache.mu.Unlock()
+	return names, nil
+}
+
+// fetchEventNames queries the event names, bypassing the cache.
+func (c *Client) fetchEventNames(ctx context.Context) ([]string, error) {
+	body, err := c.Query(ctx, "events", "names", map[string]string{"type": "general"})
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	if err := decodeJSON(body, &names); err != nil {
+		return nil, err
+	}
+	return names, nil
+}
+
+// InvalidateEventNames drops the names cached by EventNames, so the next call
+// fetches them again.
+func (c *Client) InvalidateEventNames() {
+	c.eventNames.mu.Lock()
+	c.eventNames.names = nil
+	c.eventNames.gen++
+	c.eventNames.mu.Unlock()
+}
@@ -0,0 +1,74 @@
+package mixpanel
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestEventNamesConcurrent makes two calls that only complete once both
+// requests have reached the server, so it fails if the calls are serialized.
+func TestEventNamesConcurrent(t *testing.T) {
+	for _, ttl := range []time.Duration{0, time.Minute} {
+		t.Run(ttl.String(), func(t *testing.T) {
+			arrived := make(chan struct{}, 2)
+			both := make(chan struct{})
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				arrived <- struct{}{}
+				<-both
+				w.Write([]byte(`["a","b"]`))
+			}))
+			defer srv.Close()
+			c := NewClient(WithCredentials("key", "secret"), WithEndpoint(srv.URL, srv.URL), WithEventNamesTTL(ttl))
+			var wg sync.WaitGroup
+			defer wg.Wait()
+			defer close(both)
+			for i := 0; i < 2; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					if _, err := c.EventNames(context.Background()); err != nil {
+						t.Error(err)
+					}
+				}()
+			}
+			timeout := time.After(2 * time.Second)
+			for i := 0; i < 2; i++ {
+				select {
+				case <-arrived:
+				case <-timeout:
+					t.Fatalf("%d of 2 requests reached the server, want both in flight", i)
+				}
+			}
+		})
+	}
+}
+
+func TestEventNamesCached(t *testing.T) {
+	requests := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Write([]byte(`["a","b"]`))
+	}))
+	defer srv.Close()
+	c := NewClient(WithCredentials("key", "secret"), WithEndpoint(srv.URL, srv.URL), WithEventNamesTTL(time.Minute))
+	for i := 0; i < 2; i++ {
+		if _, err := c.EventNames(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	c.InvalidateEventNames()
+	names, err := c.EventNames(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if requests != 2 {
+		t.Errorf("made %d requests, want 2", requests)
+	}
+	if len(names) != 2 || names[0] != "a" {
+		t.Errorf("names = %v, want [a b]", names)
+	}
+}
@@ -0,0 +1,228 @@
+package mixpanel
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Event is a single event as returned by the raw export endpoint. DistinctID
+// and Time are lifted out of Properties when decoding; the raw values are
+// left in Properties as well.
+type Event struct {
+	Name       string                 `json:"event"`
+	DistinctID string                 `json:"-"`
+	Time       time.Time              `json:"-"`
+	Properties map[string]interface{} `json:"properties"`
+}
+
+// UnmarshalJSON decodes an export row and populates DistinctID and Time from
+// the "distinct_id" and "time" properties. The time may be in seconds or
+// milliseconds since the epoch.
+func (e *Event) UnmarshalJSON(data []byte) error {
+	type event Event
+	var raw event
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	*e = Event(raw)
+	if id, ok := e.Properties["distinct_id"].(string); ok {
+		e.DistinctID = id
+	}
+	if t, ok := e.Properties["time"].(float64); ok {
+		e.Time = epochTime(t)
+	}
+	return nil
+}
+
+// msThreshold separates second from millisecond timestamps: as seconds it is
+// in the year 5138, as milliseconds in 1973.
+const msThreshold = 1e11
+
+// epochTime converts a Mixpanel timestamp in seconds or milliseconds to a UTC time.
+func epochTime(t float64) time.Time {
+	if t >= msThreshold || t <= -msThreshold {
+		return time.UnixMilli(int64(t)).UTC()
+	}
+	return time.Unix(int64(t), 0).UTC()
+}
+
+// InProjectTimezone reinterprets Time for projects whose exports report
+// `time` in the project's timezone rather than UTC: the wall clock of Time is
+// taken to be in loc. The raw value in Properties is left unchanged.
+func (e *Event) InProjectTimezone(loc *time.Location) {
+	if e.Time.IsZero() || loc == nil {
+		return
+	}
+	t := e.Time
+	e.Time = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), loc)
+}
+
+// MarshalJSON encodes the event in the format accepted by the import endpoint,
+// adding DistinctID and Time, in milliseconds, to the properties unless
+// already present.
+func (e Event) MarshalJSON() ([]byte, error) {
+	props := make(map[string]interface{}, len(e.Properties)+2)
+	for key, value := range e.Properties {
+		props[key] = value
+	}
+	if _, ok := props["distinct_id"]; !ok && e.DistinctID != "" {
+		props["distinct_id"] = e.DistinctID
+	}
+	if _, ok := props["time"]; !ok && !e.Time.IsZero() {
+		props["time"] = e.Time.UnixMilli()
+	}
+	return json.Marshal(struct {
+		Name       string                 `json:"event"`
+		Properties map[string]interface{} `json:"properties"`
+	}{e.Name, props})
+}
+
+// ParseEvent decodes a single line of export output into an Event.
+func ParseEvent(line []byte) (Event, error) {
+	var e Event
+	err := json.Unmarshal(line, &e)
+	return e, err
+}
+
+// ExportStream opens the raw export prepared by GetRawData and returns the
+// response body without buffering it. The export is requested gzip-compressed
+// and decompressed transparently. The caller must close the returned reader.
+func (req *Request) ExportStream(ctx context.Context) (io.ReadCloser, error) {
+	u, err := req.URL(true)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := req.sendURL(ctx, u, http.Header{"Accept-Encoding": {"gzip"}})
+	if err != nil {
+		return nil, err
+	}
+	return decompress(resp)
+}
+
+// SignedExportURL returns the signed URL of a raw export with params, valid
+// for expire, without executing it. It can be fetched by anyone holding it
+// until then, so share it with care. It is not available with a service
+// account, whose credentials are not part of the URL.
+func (req *Request) SignedExportURL(params map[string]string, expire time.Duration) (string, error) {
+	if expire <= 0 {
+		return "", fmt.Errorf("mixpanel: export URL expiry must be positive, got %v", expire)
+	}
+	if req.usesServiceAccount() {
+		return "", errors.New("mixpanel: signed URLs require an API secret, not a service account")
+	}
+	if err := checkRequired("export", "", params); err != nil {
+		return "", err
+	}
+	if err := req.checkFormat(); err != nil {
+		return "", err
+	}
+	seconds := int((expire + time.Second - 1) / time.Second)
+	return req.CreateRequest(true, "export", "", seconds, params), nil
+}
+
+// ExportTo streams an export prepared with GetRawData like ExportStream and
+// copies the decompressed body to w, returning the number of bytes written.
+// The copy stops as soon as ctx is done.
+func (req *Request) ExportTo(ctx context.Context, w io.Writer) (int64, error) {
+	body, err := req.ExportStream(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer body.Close()
+	return io.Copy(w, ctxReader{ctx, body})
+}
+
+// ExportChan streams an export prepared with GetRawData like ExportStream and
+// sends each decoded event on the first channel. The events channel is
+// unbuffered, so a slow consumer slows the read of the response. When the
+// export ends, fails or ctx is done, the error channel receives the error, if
+// any, and both channels are closed.
+func (req *Request) ExportChan(ctx context.Context) (<-chan Event, <-chan error) {
+	events := make(chan Event)
+	errc := make(chan error, 1)
+	go func() {
+		defer close(errc)
+		defer close(events)
+		body, err := req.ExportStream(ctx)
+		if err != nil {
+			errc <- err
+			return
+		}
+		defer body.Close()
+		err = DecodeExport(body, func(e Event) error {
+			select {
+			case events <- e:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		})
+		if err != nil {
+			errc <- err
+		}
+	}()
+	return events, errc
+}
+
+// ctxReader fails reads once ctx is done.
+type ctxReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (c ctxReader) Read(p []byte) (int, error) {
+	if err := c.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return c.r.Read(p)
+}
+
+// gzipReadCloser closes both the gzip reader and the underlying body.
+type gzipReadCloser struct {
+	*gzip.Reader
+	body io.Closer
+}
+
+func (g *gzipReadCloser) Close() error {
+	g.Reader.Close()
+	return g.body.Close()
+}
+
+// decompress returns the body of resp, wrapped in a gzip reader if the
+// response is gzip-encoded.
+func decompress(resp *http.Response) (io.ReadCloser, error) {
+	if !strings.EqualFold(resp.Header.Get("Content-Encoding"), "gzip") {
+		return resp.Body, nil
+	}
+	zr, err := gzip.NewReader(resp.Body)
+	if err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+	return &gzipReadCloser{Reader: zr, body: resp.Body}, nil
+}
+
+// DecodeExport reads newline-delimited JSON events from r, as returned by the
+// export endpoint, and calls fn for each one. Decoding stops at the first
+// error returned by fn. Only one event is held in memory at a time.
+func DecodeExport(r io.Reader, fn func(Event) error) error {
+	dec := json.NewDecoder(r)
+	for {
+		var e Event
+		if err := dec.Decode(&e); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if err := fn(e); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package mixpanel
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"testing"
+)
+
+func TestExportGzip(t *testing.T) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	zw.Write([]byte(`{"event":"a","properties":{"distinct_id":"u1","time":1600000000}}` + "\n" +
+		`{"event":"b","properties":{"time":1600000000123}}` + "\n"))
+	zw.Close()
+	hc := &http.Client{Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		if r.Header.Get("Accept-Encoding") != "gzip" {
+			t.Errorf("Accept-Encoding = %q, want gzip", r.Header.Get("Accept-Encoding"))
+		}
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Content-Encoding": {"gzip"}},
+			Body:       ioutil.NopCloser(bytes.NewReader(buf.Bytes())),
+			Request:    r,
+		}, nil
+	})}
+	c := NewClient(WithCredentials("key", "secret"), WithHTTPClient(hc))
+	body, err := c.Export(context.Background(), map[string]string{"from_date": "2020-01-01", "to_date": "2020-01-02"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer body.Close()
+	var events []Event
+	if err := DecodeExport(body, func(e Event) error {
+		events = append(events, e)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 2 {
+		t.Fatalf("decoded %d events, want 2", len(events))
+	}
+	if events[0].DistinctID != "u1" || events[0].Time.Unix() != 1600000000 {
+		t.Errorf("first event = %+v", events[0])
+	}
+	if events[1].Time.UnixMilli() != 1600000000123 {
+		t.Errorf("second event time = %v, want 1600000000123ms", events[1].Time.UnixMilli())
+	}
+}
@@ -0,0 +1,96 @@
+package mixpanel
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+)
+
+// Compliance types accepted by CreateDeletionTask.
+const (
+	ComplianceGDPR = "GDPR"
+	ComplianceCCPA = "CCPA"
+)
+
+// deletionResponse is the envelope returned by the data deletion API.
+type deletionResponse struct {
+	Status  string `json:"status"`
+	Results struct {
+		TaskID string `json:"task_id"`
+		Status string `json:"status"`
+	} `json:"results"`
+}
+
+// CreateDeletionTask asks Mixpanel to delete all data for distinctIDs under
+// complianceType (ComplianceGDPR or ComplianceCCPA). Deletion runs
+// asynchronously; the returned task id can be passed to GetDeletionTaskStatus.
+// Requires Config.Token and Config.OAuthToken.
+func (c *Client) CreateDeletionTask(distinctIDs []string, complianceType string) (taskID string, err error) {
+	return c.CreateDeletionTaskContext(context.Background(), distinctIDs, complianceType)
+}
+
+// CreateDeletionTaskContext is CreateDeletionTask with a context.
+func (c *Client) CreateDeletionTaskContext(ctx context.Context, distinctIDs []string, complianceType string) (string, error) {
+	if len(distinctIDs) == 0 {
+		return "", errors.New("mixpanel: no distinct_ids to delete")
+	}
+	if complianceType == "" {
+		complianceType = ComplianceGDPR
+	}
+	uri, header, err := c.config.deletionRequest("")
+	if err != nil {
+		return "", err
+	}
+	var out deletionResponse
+	err = c.config.sendJSON(ctx, "data-deletions", http.MethodPost, uri, header, map[string]interface{}{
+		"distinct_ids":    distinctIDs,
+		"compliance_type": complianceType,
+	}, &out)
+	if err != nil {
+		return "", err
+	}
+	if out.Results.TaskID == "" {
+		return "", errors.New("mixpanel: data deletion response has no task_id")
+	}
+	return out.Results.TaskID, nil
+}
+
+// GetDeletionTaskStatus returns the status of a deletion task, one of
+// PENDING, STAGING, STARTED, SUCCESS, FAILURE, REVOKED, NOT_FOUND or UNKNOWN.
+func (c *Client) GetDeletionTaskStatus(taskID string) (string, error) {
+	return c.GetDeletionTaskStatusContext(context.Background(), taskID)
+}
+
+// GetDeletionTaskStatusContext is GetDeletionTaskStatus with a context.
+func (c *Client) GetDeletionTaskStatusContext(ctx context.Context, taskID string) (string, error) {
+	if taskID == "" {
+		return "", errors.New("mixpanel: missing task_id")
+	}
+	uri, header, err := c.config.deletionRequest(taskID)
+	if err != nil {
+		return "", err
+	}
+	var out deletionResponse
+	if err := c.config.sendJSON(ctx, "data-deletions", http.MethodGet, uri, header, nil, &out); err != nil {
+		return "", err
+	}
+	return out.Results.Status, nil
+}
+
+// deletionRequest returns the URL and auth headers for the data deletion API,
+// optionally scoped to a task.
+func (c *Config) deletionRequest(taskID string) (string, http.Header, error) {
+	if c.Token == "" {
+		return "", nil, errMissingToken
+	}
+	if c.OAuthToken == "" {
+		return "", nil, errors.New("mixpanel: missing OAuth token")
+	}
+	uri := c.queryEndpoint() + "/app/data-deletions/v3.0/"
+	if taskID != "" {
+		uri += url.PathEscape(taskID)
+	}
+	uri += "?" + url.Values{"token": {c.Token}}.Encode()
+	return uri, http.Header{"Authorization": {"Bearer " + c.OAuthToken}}, nil
+}
@@ -0,0 +1,62 @@
+package mixpanel
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+)
+
+// GroupContext applies the group profile operation op, such as "$set" or
+// "$unset", with value to the group identified by groupKey and groupID.
+func (c *Client) GroupContext(ctx context.Context, groupKey string, groupID string, op string, value interface{}) error {
+	if c.config.Token == "" {
+		return errMissingToken
+	}
+	if groupKey == "" {
+		return errors.New("mixpanel: missing group_key")
+	}
+	if groupID == "" {
+		return errors.New("mixpanel: missing group_id")
+	}
+	payload, err := json.Marshal(map[string]interface{}{
+		"$token":     c.config.Token,
+		"$group_key": groupKey,
+		"$group_id":  groupID,
+		op:           value,
+	})
+	if err != nil {
+		return err
+	}
+	return c.config.postIngest(ctx, "groups", payload)
+}
+
+// GroupSet sets properties on the group profile, overwriting existing values.
+func (c *Client) GroupSet(groupKey string, groupID string, properties map[string]interface{}) error {
+	return c.GroupContext(context.Background(), groupKey, groupID, "$set", properties)
+}
+
+// GroupSetOnce sets properties on the group profile only where they are not already set.
+func (c *Client) GroupSetOnce(groupKey string, groupID string, properties map[string]interface{}) error {
+	return c.GroupContext(context.Background(), groupKey, groupID, "$set_once", properties)
+}
+
+// GroupUnion merges values into list properties on the group profile,
+// ignoring values already present.
+func (c *Client) GroupUnion(groupKey string, groupID string, values map[string][]interface{}) error {
+	return c.GroupContext(context.Background(), groupKey, groupID, "$union", values)
+}
+
+// GroupUnset removes the named properties from the group profile.
+func (c *Client) GroupUnset(groupKey string, groupID string, names []string) error {
+	return c.GroupContext(context.Background(), groupKey, groupID, "$unset", names)
+}
+
+// GroupRemove removes values from list properties on the group profile.
+func (c *Client) GroupRemove(groupKey string, groupID string, values map[string]interface{}) error {
+	return c.GroupContext(context.Background(), groupKey, groupID, "$remove", values)
+}
+
+// GroupDeleteGroup deletes the group profile.
+func (c *Client) GroupDeleteGroup(groupKey string, groupID string) error {
+	return c.GroupContext(context.Background(), groupKey, groupID, "$delete", "")
+}
@@ -0,0 +1,138 @@
+package mixpanel
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"time"
+)
+
+// bodyPrefixSize is how much of a response body is passed to OnResponse.
+const bodyPrefixSize = 512
+
+// RequestInfo describes an outbound request for the OnRequest hook. The
+// Authorization header is masked; the URL carries the signature but never the
+// API secret.
+type RequestInfo struct {
+	Method  string
+	URL     string
+	Header  http.Header
+	Attempt int
+}
+
+// ResponseInfo describes the outcome of a request for the OnResponse hook.
+// StatusCode is zero and Err is set if no response was received.
+type ResponseInfo struct {
+	Request    RequestInfo
+	StatusCode int
+	Header     http.Header
+	BodyPrefix []byte
+	Duration   time.Duration
+	Err        error
+}
+
+// RequestMetrics describes a completed call for the OnMetrics hook. A call
+// covers all attempts of one request, so Retries counts the attempts after the
+// first and Duration includes the delays between them. StatusCode is that of
+// the last response, or zero if none was received. Endpoint names the API
+// called, such as "funnels/list", "import" or "data-deletions", and never
+// carries ids, so it can be used as a metric label.
+type RequestMetrics struct {
+	Method     string
+	Endpoint   string
+	StatusCode int
+	Retries    int
+	Duration   time.Duration
+	Err        error
+}
+
+// StatusClass returns the class of StatusCode, such as "2xx", or "error" if
+// no response was received, for use as a metric label.
+func (m RequestMetrics) StatusClass() string {
+	switch m.StatusCode / 100 {
+	case 1:
+		return "1xx"
+	case 2:
+		return "2xx"
+	case 3:
+		return "3xx"
+	case 4:
+		return "4xx"
+	case 5:
+		return "5xx"
+	}
+	return "error"
+}
+
+// Tracer wraps each HTTP request in a span. StartSpan is called before the
+// request is sent; the returned context is attached to the request, so
+// propagators can inject headers, and end is called with the outcome. An
+// adapter for OpenTelemetry or any other tracing library is a few lines.
+type Tracer interface {
+	StartSpan(ctx context.Context, info RequestInfo) (spanCtx context.Context, end func(ResponseInfo))
+}
+
+// requestInfo returns the hook description of hreq.
+func requestInfo(hreq *http.Request, attempt int) RequestInfo {
+	header := hreq.Header.Clone()
+	if header.Get("Authorization") != "" {
+		header.Set("Authorization", "****")
+	}
+	return RequestInfo{
+		Method:  hreq.Method,
+		URL:     hreq.URL.String(),
+		Header:  header,
+		Attempt: attempt,
+	}
+}
+
+// prefixedBody replays a peeked prefix before the rest of a response body.
+type prefixedBody struct {
+	io.Reader
+	io.Closer
+}
+
+// peekBody returns the first bytes of resp.Body without consuming them.
+func peekBody(resp *http.Response) []byte {
+	prefix := make([]byte, bodyPrefixSize)
+	n, _ := io.ReadFull(resp.Body, prefix)
+	prefix = prefix[:n]
+	resp.Body = prefixedBody{io.MultiReader(bytes.NewReader(prefix), resp.Body), resp.Body}
+	return prefix
+}
+
+// roundTrip sends hreq once, calling the OnRequest and OnResponse hooks
+// around it and recording a span if a Tracer is set.
+func (c *Config) roundTrip(hreq *http.Request, attempt int) (*http.Response, error) {
+	if c.OnRequest == nil && c.OnResponse == nil && c.Tracer == nil {
+		return c.httpClient().Do(hreq)
+	}
+	info := requestInfo(hreq, attempt)
+	var end func(ResponseInfo)
+	if c.Tracer != nil {
+		var ctx context.Context
+		ctx, end = c.Tracer.StartSpan(hreq.Context(), info)
+		hreq = hreq.WithContext(ctx)
+	}
+	if c.OnRequest != nil {
+		c.OnRequest(info)
+	}
+	start := time.Now()
+	resp, err := c.httpClient().Do(hreq)
+	ri := ResponseInfo{Request: info, Duration: time.Since(start), Err: err}
+	if resp != nil {
+		ri.StatusCode = resp.StatusCode
+		ri.Header = resp.Header
+		if c.OnResponse != nil {
+			ri.BodyPrefix = peekBody(resp)
+		}
+	}
+	if end != nil {
+		end(ri)
+	}
+	if c.OnResponse != nil {
+		c.OnResponse(ri)
+	}
+	return resp, err
+}
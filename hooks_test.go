@@ -0,0 +1,51 @@
+package mixpanel
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestOnMetrics(t *testing.T) {
+	hc, _ := fakeResponses(http.StatusTooManyRequests, http.StatusOK)
+	var got []RequestMetrics
+	req := NewRequestWithConfig(Config{APIKey: "key", APISecret: "secret", HTTPClient: hc,
+		MaxRetries: 1, BaseBackoff: time.Millisecond,
+		OnMetrics: func(m RequestMetrics) { got = append(got, m) }})
+	req.CreateRequest(false, "funnels", "list", 0, nil)
+	if _, err := req.Do(false); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 {
+		t.Fatalf("OnMetrics called %d times, want 1", len(got))
+	}
+	m := got[0]
+	if m.Method != http.MethodGet || m.Endpoint != "funnels/list" {
+		t.Errorf("request = %s %s, want GET funnels/list", m.Method, m.Endpoint)
+	}
+	if m.StatusCode != http.StatusOK || m.StatusClass() != "2xx" {
+		t.Errorf("status = %d (%s), want 200 (2xx)", m.StatusCode, m.StatusClass())
+	}
+	if m.Retries != 1 {
+		t.Errorf("retries = %d, want 1", m.Retries)
+	}
+	if m.Duration < time.Millisecond/2 {
+		t.Errorf("duration = %v, want at least the backoff", m.Duration)
+	}
+	if m.Err != nil {
+		t.Errorf("err = %v", m.Err)
+	}
+}
+
+func TestOnMetricsEndpointHasNoIDs(t *testing.T) {
+	hc, _ := fakeResponses(http.StatusOK)
+	var got RequestMetrics
+	c := NewClient(WithConfig(Config{Token: "token", OAuthToken: "oauth", HTTPClient: hc,
+		OnMetrics: func(m RequestMetrics) { got = m }}))
+	if _, err := c.GetDeletionTaskStatus("task-1234"); err != nil {
+		t.Fatal(err)
+	}
+	if got.Endpoint != "data-deletions" {
+		t.Errorf("endpoint = %q, want data-deletions", got.Endpoint)
+	}
+}
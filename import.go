@@ -0,0 +1,287 @@
+package mixpanel
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+// ImportBatchSize is the maximum number of events sent in a single /import request.
+const ImportBatchSize = 2000
+
+// MaxImportBytes is the largest uncompressed payload sent to /import in one
+// request. Larger batches are split in half until they fit.
+const MaxImportBytes = 10 << 20
+
+// maxImportSplits caps how many times a batch is halved, enough to reduce
+// ImportBatchSize events to single events.
+const maxImportSplits = 12
+
+// errPayloadTooLarge is returned for batches over MaxImportBytes.
+var errPayloadTooLarge = errors.New("mixpanel: import payload too large")
+
+// DefaultCompressThreshold is the payload size from which imports are
+// compressed when Config.CompressThreshold is zero.
+const DefaultCompressThreshold = 4 << 10
+
+// Import sends events to the /import endpoint in batches of at most
+// ImportBatchSize, authenticating with the service account if one is
+// configured and with the API secret otherwise. Events without a time are
+// stamped with the current time; /import accepts seconds or milliseconds
+// since the epoch; Import sends milliseconds. Events without an $insert_id
+// are given one by InsertID unless Config.DisableInsertID is set, so a retried
+// import does not create duplicates. Failed batches do not stop the import;
+// their errors are combined in the returned error.
+func (c *Client) Import(events []Event) error {
+	return c.ImportContext(context.Background(), events)
+}
+
+// ImportContext is Import with a context, which is checked between batches.
+func (c *Client) ImportContext(ctx context.Context, events []Event) error {
+	_, err := c.importAll(ctx, events, false)
+	return err
+}
+
+// ImportFailure describes an event rejected by a verbose import. Index is the
+// position of the event in the slice passed to ImportVerbose.
+type ImportFailure struct {
+	Index  int
+	Reason string
+}
+
+// ImportVerbose is Import in verbose mode: events rejected individually by
+// Mixpanel are reported as failures rather than failing their whole batch, so
+// that only they need to be retried. The error covers batches that failed
+// outright.
+func (c *Client) ImportVerbose(ctx context.Context, events []Event) ([]ImportFailure, error) {
+	return c.importAll(ctx, events, true)
+}
+
+// importAll imports events batch by batch, collecting failures and errors.
+func (c *Client) importAll(ctx context.Context, events []Event, verbose bool) ([]ImportFailure, error) {
+	var (
+		failures []ImportFailure
+		errs     []error
+	)
+	for start := 0; start < len(events); start += ImportBatchSize {
+		if err := ctx.Err(); err != nil {
+			errs = append(errs, err)
+			break
+		}
+		end := start + ImportBatchSize
+		if end > len(events) {
+			end = len(events)
+		}
+		batch := prepareImport(events[start:end], c.config.now(), !c.config.DisableInsertID)
+		f, err := c.config.importSplitting(ctx, batch, start, verbose, 0)
+		failures = append(failures, f...)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("mixpanel: import batch %d-%d: %w", start, end-1, err))
+		}
+	}
+	return failures, errors.Join(errs...)
+}
+
+// importResponse is the body returned by /import.
+type importResponse struct {
+	FailedRecords []struct {
+		Index   int    `json:"index"`
+		Field   string `json:"field"`
+		Message string `json:"message"`
+	} `json:"failed_records"`
+}
+
+// importFailures extracts the rejected records of an /import response, with
+// indexes shifted by offset.
+func importFailures(body []byte, offset int) []ImportFailure {
+	var res importResponse
+	if json.Unmarshal(body, &res) != nil {
+		return nil
+	}
+	failures := make([]ImportFailure, 0, len(res.FailedRecords))
+	for _, r := range res.FailedRecords {
+		reason := r.Message
+		if r.Field != "" {
+			reason = r.Field + ": " + r.Message
+		}
+		failures = append(failures, ImportFailure{Index: offset + r.Index, Reason: reason})
+	}
+	return failures
+}
+
+// InsertID returns a deterministic $insert_id for e derived from its name,
+// distinct_id and time, so that re-importing the same event is deduplicated
+// by Mixpanel. Time is used in milliseconds, as sent by Import, and a numeric
+// `time` property gives the same id whatever its Go type.
+func InsertID(e Event) string {
+	distinctID := e.DistinctID
+	if id, ok := e.Properties["distinct_id"]; ok {
+		distinctID = fmt.Sprint(id)
+	}
+	t := strconv.FormatInt(e.Time.UnixMilli(), 10)
+	if v, ok := e.Properties["time"]; ok {
+		t = numberString(v)
+	}
+	return MD5Hash(e.Name + "\x00" + distinctID + "\x00" + t)
+}
+
+// numberString formats v in a canonical form if it is a number, so that 1700000000
+// as an int, a float64 or a json.Number formats alike, and with fmt otherwise.
+func numberString(v interface{}) string {
+	if n, ok := v.(json.Number); ok {
+		if f, err := n.Float64(); err == nil {
+			return strconv.FormatFloat(f, 'f', -1, 64)
+		}
+		return n.String()
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(rv.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(rv.Uint(), 10)
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(rv.Float(), 'f', -1, 64)
+	}
+	return fmt.Sprint(v)
+}
+
+// prepareImport returns events with Time set to now for every event without
+// a time, and, if insertIDs is set, an $insert_id generated by InsertID for
+// every event that lacks one. The given events are not modified.
+func prepareImport(events []Event, now time.Time, insertIDs bool) []Event {
+	out := make([]Event, len(events))
+	for i, e := range events {
+		if _, ok := e.Properties["time"]; !ok && e.Time.IsZero() {
+			e.Time = now
+		}
+		if _, ok := e.Properties["$insert_id"]; !ok && insertIDs {
+			props := make(map[string]interface{}, len(e.Properties)+1)
+			for key, value := range e.Properties {
+				props[key] = value
+			}
+			props["$insert_id"] = InsertID(e)
+			e.Properties = props
+		}
+		out[i] = e
+	}
+	return out
+}
+
+// importSplitting is importBatch, halving the batch and importing each half
+// separately when it is too large, either by MaxImportBytes or by Mixpanel
+// answering 413.
+func (c *Config) importSplitting(ctx context.Context, events []Event, offset int, verbose bool, depth int) ([]ImportFailure, error) {
+	failures, err := c.importBatch(ctx, events, offset, verbose)
+	if !tooLarge(err) {
+		return failures, err
+	}
+	if len(events) == 1 {
+		return nil, fmt.Errorf("mixpanel: event %d alone exceeds the import size limit: %w", offset, err)
+	}
+	if depth >= maxImportSplits {
+		return nil, err
+	}
+	mid := len(events) / 2
+	f1, err1 := c.importSplitting(ctx, events[:mid], offset, verbose, depth+1)
+	f2, err2 := c.importSplitting(ctx, events[mid:], offset+mid, verbose, depth+1)
+	return append(f1, f2...), errors.Join(err1, err2)
+}
+
+// tooLarge reports whether err means an import payload was too large.
+func tooLarge(err error) bool {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode == http.StatusRequestEntityTooLarge
+	}
+	return errors.Is(err, errPayloadTooLarge)
+}
+
+// importBatch posts a single prepared batch of events to /import. offset is
+// the index of the batch's first event in the whole import. In verbose mode,
+// records rejected individually are returned as failures instead of an error.
+func (c *Config) importBatch(ctx context.Context, events []Event, offset int, verbose bool) ([]ImportFailure, error) {
+	payload, err := json.Marshal(events)
+	if err != nil {
+		return nil, err
+	}
+	if len(payload) > MaxImportBytes {
+		return nil, errPayloadTooLarge
+	}
+	header := c.basicAuthHeader("application/json")
+	if c.CompressImports && len(payload) >= c.compressThreshold() {
+		if payload, err = gzipBytes(payload); err != nil {
+			return nil, err
+		}
+		header.Set("Content-Encoding", "gzip")
+	}
+	resp, err := c.sendBody(ctx, "import", http.MethodPost, c.importURL(verbose), payload, header)
+	if err == nil {
+		var body []byte
+		if body, err = c.readBody(ctx, resp); err == nil {
+			return importFailures(body, offset), nil
+		}
+	}
+	var apiErr *APIError
+	if verbose && errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusBadRequest {
+		if failures := importFailures(apiErr.Body, offset); len(failures) > 0 {
+			return failures, nil
+		}
+	}
+	return nil, err
+}
+
+func (c *Config) compressThreshold() int {
+	if c.CompressThreshold > 0 {
+		return c.CompressThreshold
+	}
+	return DefaultCompressThreshold
+}
+
+// gzipBytes returns p gzip-compressed.
+func gzipBytes(p []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(p); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// importURL returns the /import URL, scoped to ProjectID when set.
+func (c *Config) importURL(verbose bool) string {
+	q := url.Values{"strict": {"1"}}
+	if verbose {
+		q.Set("verbose", "1")
+	}
+	if c.ProjectID != "" {
+		q.Set("project_id", c.ProjectID)
+	}
+	return c.ingestEndpoint() + "/import?" + q.Encode()
+}
+
+// basicAuthHeader returns headers with the given content type, if any, and HTTP
+// Basic auth using the service account, or the API secret when none is configured.
+func (c *Config) basicAuthHeader(contentType string) http.Header {
+	hreq := &http.Request{Header: make(http.Header)}
+	if contentType != "" {
+		hreq.Header.Set("Content-Type", contentType)
+	}
+	if c.ServiceAccountUser != "" {
+		hreq.SetBasicAuth(c.ServiceAccountUser, c.ServiceAccountSecret)
+	} else {
+		hreq.SetBasicAuth(c.APISecret, "")
+	}
+	return hreq.Header
+}
@@ -0,0 +1,29 @@
+package mixpanel
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestInsertIDMilliseconds(t *testing.T) {
+	a := Event{Name: "Signup", DistinctID: "u1", Time: time.UnixMilli(1700000000100)}
+	b := Event{Name: "Signup", DistinctID: "u1", Time: time.UnixMilli(1700000000900)}
+	if InsertID(a) == InsertID(b) {
+		t.Error("events 800ms apart share an $insert_id")
+	}
+}
+
+func TestInsertIDTimeProperty(t *testing.T) {
+	ids := make(map[string]bool)
+	for _, v := range []interface{}{1700000000100, int64(1700000000100), float64(1700000000100), json.Number("1700000000100")} {
+		ids[InsertID(Event{Name: "Signup", Properties: map[string]interface{}{"distinct_id": "u1", "time": v}})] = true
+	}
+	if len(ids) != 1 {
+		t.Errorf("numeric time types give %d distinct ids, want 1", len(ids))
+	}
+	fromTime := InsertID(Event{Name: "Signup", DistinctID: "u1", Time: time.UnixMilli(1700000000100)})
+	if !ids[fromTime] {
+		t.Error("Time and an equal `time` property in milliseconds give different ids")
+	}
+}
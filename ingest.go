@@ -0,0 +1,99 @@
+package mixpanel
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// ErrRejected is returned when the ingestion API answers 0, meaning the data
+// was not accepted.
+var ErrRejected = errors.New("mixpanel: data rejected by ingestion API")
+
+// errMissingToken is returned by ingestion calls made without a project token.
+var errMissingToken = errors.New("mixpanel: missing project token")
+
+// Track sends a single event for distinctID. The project token is added to
+// properties, which is not modified, and so is the current time unless
+// properties has a `time` already. /track expects the time in seconds since
+// the epoch.
+func (c *Client) Track(event string, distinctID string, properties map[string]interface{}) error {
+	return c.TrackContext(context.Background(), event, distinctID, properties)
+}
+
+// TrackContext is Track with a context.
+func (c *Client) TrackContext(ctx context.Context, event string, distinctID string, properties map[string]interface{}) error {
+	return c.TrackAtContext(ctx, event, distinctID, c.config.now(), properties)
+}
+
+// TrackAt is Track with the event happening at t rather than now. A `time`
+// in properties still takes precedence.
+func (c *Client) TrackAt(event string, distinctID string, t time.Time, properties map[string]interface{}) error {
+	return c.TrackAtContext(context.Background(), event, distinctID, t, properties)
+}
+
+// TrackAtContext is TrackAt with a context.
+func (c *Client) TrackAtContext(ctx context.Context, event string, distinctID string, t time.Time, properties map[string]interface{}) error {
+	if c.config.Token == "" {
+		return errMissingToken
+	}
+	props := make(map[string]interface{}, len(properties)+3)
+	for key, value := range properties {
+		props[key] = value
+	}
+	props["token"] = c.config.Token
+	if _, ok := props["time"]; !ok {
+		props["time"] = t.Unix()
+	}
+	if distinctID != "" {
+		props["distinct_id"] = distinctID
+	}
+	payload, err := json.Marshal(map[string]interface{}{
+		"event":      event,
+		"properties": props,
+	})
+	if err != nil {
+		return err
+	}
+	return c.config.postIngest(ctx, "track", payload)
+}
+
+// Alias links aliasID to distinctID by sending a $create_alias event, so that
+// events tracked under either id are attributed to the same user.
+func (c *Client) Alias(distinctID string, aliasID string) error {
+	return c.AliasContext(context.Background(), distinctID, aliasID)
+}
+
+// AliasContext is Alias with a context.
+func (c *Client) AliasContext(ctx context.Context, distinctID string, aliasID string) error {
+	if distinctID == "" {
+		return errMissingDistinctID
+	}
+	if aliasID == "" {
+		return errors.New("mixpanel: missing alias")
+	}
+	return c.TrackContext(ctx, "$create_alias", distinctID, map[string]interface{}{"alias": aliasID})
+}
+
+// postIngest sends payload base64-encoded as the `data` form field to path on
+// the ingestion endpoint, mapping a 0 response to ErrRejected.
+func (c *Config) postIngest(ctx context.Context, path string, payload []byte) error {
+	form := url.Values{"data": {base64.StdEncoding.EncodeToString(payload)}}
+	resp, err := c.sendBody(ctx, path, http.MethodPost, c.ingestEndpoint()+"/"+path, []byte(form.Encode()), formHeader())
+	if err != nil {
+		return err
+	}
+	body, err := c.readBody(ctx, resp)
+	if err != nil {
+		return err
+	}
+	if strings.TrimSpace(string(body)) != "1" {
+		return ErrRejected
+	}
+	return nil
+}
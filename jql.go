@@ -0,0 +1,32 @@
+package mixpanel
+
+import (
+	"encoding/json"
+	"io"
+	"io/ioutil"
+)
+
+// RunJQL executes a JQL script and returns the raw JSON result. scriptParams,
+// if non-nil, is made available to the script as the global `params`. The
+// script is POSTed since it is usually too large for a query string.
+func (req *Request) RunJQL(script string, scriptParams map[string]interface{}) ([]byte, error) {
+	params := map[string]string{"script": script}
+	if scriptParams != nil {
+		encoded, err := json.Marshal(scriptParams)
+		if err != nil {
+			return nil, err
+		}
+		params["params"] = string(encoded)
+	}
+	req.CreateRequest(false, "jql", "", 0, params)
+	return req.DoPost(false)
+}
+
+// RunJQLReader is RunJQL with the script read from r.
+func (req *Request) RunJQLReader(r io.Reader, scriptParams map[string]interface{}) ([]byte, error) {
+	script, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return req.RunJQL(string(script), scriptParams)
+}
@@ -0,0 +1,81 @@
+package mixpanel
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+)
+
+// Lexicon entity types.
+const (
+	LexiconEvent   = "event"
+	LexiconProfile = "profile"
+)
+
+// LexiconSchema is the Lexicon entry for an event or profile property set.
+// SchemaJSON holds the JSON Schema, including descriptions and metadata such
+// as `$hidden`.
+type LexiconSchema struct {
+	EntityType string                 `json:"entityType"`
+	Name       string                 `json:"name"`
+	SchemaJSON map[string]interface{} `json:"schemaJson"`
+}
+
+// GetLexiconSchemas returns all Lexicon schemas of the project. Requires a
+// service account and Config.ProjectID.
+func (c *Client) GetLexiconSchemas() ([]LexiconSchema, error) {
+	return c.GetLexiconSchemasContext(context.Background())
+}
+
+// GetLexiconSchemasContext is GetLexiconSchemas with a context.
+func (c *Client) GetLexiconSchemasContext(ctx context.Context) ([]LexiconSchema, error) {
+	uri, err := c.config.schemasURL()
+	if err != nil {
+		return nil, err
+	}
+	var out struct {
+		Results []LexiconSchema `json:"results"`
+	}
+	if err := c.config.sendJSON(ctx, "schemas", http.MethodGet, uri, c.config.basicAuthHeader(""), nil, &out); err != nil {
+		return nil, err
+	}
+	return out.Results, nil
+}
+
+// SetLexiconSchema creates or replaces the schema named schema.Name for
+// entityType (LexiconEvent or LexiconProfile).
+func (c *Client) SetLexiconSchema(entityType string, schema LexiconSchema) error {
+	return c.SetLexiconSchemaContext(context.Background(), entityType, schema)
+}
+
+// SetLexiconSchemaContext is SetLexiconSchema with a context.
+func (c *Client) SetLexiconSchemaContext(ctx context.Context, entityType string, schema LexiconSchema) error {
+	if entityType == "" {
+		return errors.New("mixpanel: missing lexicon entity type")
+	}
+	if schema.Name == "" {
+		return errors.New("mixpanel: missing lexicon schema name")
+	}
+	uri, err := c.config.schemasURL()
+	if err != nil {
+		return err
+	}
+	uri += "/" + url.PathEscape(entityType) + "/" + url.PathEscape(schema.Name)
+	body := schema.SchemaJSON
+	if body == nil {
+		body = map[string]interface{}{}
+	}
+	return c.config.sendJSON(ctx, "schemas", http.MethodPost, uri, c.config.basicAuthHeader(""), body, nil)
+}
+
+// schemasURL returns the Lexicon schemas URL of the project.
+func (c *Config) schemasURL() (string, error) {
+	if err := c.checkServiceAccount(); err != nil {
+		return "", err
+	}
+	if c.ProjectID == "" {
+		return "", errors.New("mixpanel: missing project_id")
+	}
+	return c.queryEndpoint() + "/app/projects/" + url.PathEscape(c.ProjectID) + "/schemas", nil
+}
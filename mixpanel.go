@@ -4,9 +4,15 @@ package mixpanel
 
 import (
 	"crypto/md5"
+	"crypto/subtle"
+	"crypto/tls"
 	"encoding/hex"
+	"fmt"
+	"io"
 	"io/ioutil"
-	"log"
+	"net/http"
+	"net/url"
+	"os"
 	"sort"
 	"strconv"
 	"strings"
@@ -15,16 +21,43 @@ import (
 
 const (
 	// Endpoint const
-	Endpoint string = "http://mixpanel.com/api"
+	Endpoint string = "https://mixpanel.com/api"
 	// RawEndpoint const
-	RawEndpoint string = "http://data.mixpanel.com/api"
-	// Version const
+	RawEndpoint string = "https://data.mixpanel.com/api"
+	// EUEndpoint is the query endpoint for projects with EU data residency.
+	EUEndpoint string = "https://eu.mixpanel.com/api"
+	// EURawEndpoint is the raw export endpoint for projects with EU data residency.
+	EURawEndpoint string = "https://data-eu.mixpanel.com/api"
+	// IngestEndpoint is the endpoint events and profile updates are sent to.
+	IngestEndpoint string = "https://api.mixpanel.com"
+	// EUIngestEndpoint is the ingestion endpoint for projects with EU data residency.
+	EUIngestEndpoint string = "https://api-eu.mixpanel.com"
+	// QueryAPIEndpoint is the query endpoint on api.mixpanel.com, which
+	// replaces Endpoint and requires a service account and project id.
+	QueryAPIEndpoint string = "https://api.mixpanel.com/api"
+	// EUQueryAPIEndpoint is QueryAPIEndpoint for projects with EU data residency.
+	EUQueryAPIEndpoint string = "https://api-eu.mixpanel.com/api"
+	// Version is the default API version.
 	Version string = "2.0"
-	// Format const
+	// Format is the default response format.
 	Format string = "json"
+	// FormatCSV requests CSV output from endpoints that support it.
+	FormatCSV string = "csv"
+	// DefaultExpire is how long signed requests stay valid by default.
+	DefaultExpire = 600 * time.Second
+	// DefaultMaxResponseBytes is the default Config.MaxResponseBytes.
+	DefaultMaxResponseBytes = 32 << 20
+	// DefaultMaxQueryLength is the default Config.MaxQueryLength, below the
+	// common 8 KB limit on request lines.
+	DefaultMaxQueryLength = 7000
+	// PackageVersion is the version of this package, sent in the User-Agent.
+	PackageVersion string = "0.1.0"
+	// DefaultUserAgent is the User-Agent sent when Config.UserAgent is empty.
+	DefaultUserAgent string = "Lanzafame-mixpanel/" + PackageVersion
 )
 
-// Request object
+// Request object. A Request holds the state of a single call and is not safe
+// for concurrent use; use a Client to issue requests from multiple goroutines.
 type Request struct {
 	Endpoint   string
 	Method     string
@@ -38,14 +71,245 @@ type Request struct {
 type Config struct {
 	APIKey    string
 	APISecret string
+	// Version overrides the default API version when set. Since each Request
+	// holds its own copy of the Config, setting it on a Request only affects
+	// that request.
+	Version string
+	// DefaultExpire is how long signed requests stay valid when CreateRequest
+	// is given no expiry. Defaults to the DefaultExpire constant when zero.
+	DefaultExpire time.Duration
+	// Format is the response format, Format (json) or FormatCSV. Defaults to
+	// Format when empty.
+	Format string
+	// QueryEndpoint and RawEndpoint override the default US hosts when set.
+	QueryEndpoint string
+	RawEndpoint   string
+	// IngestEndpoint overrides the default ingestion host when set.
+	IngestEndpoint string
+	// Token is the project token used when sending events and profile updates.
+	Token string
+	// ServiceAccountUser and ServiceAccountSecret authenticate with HTTP Basic
+	// auth. When set, query requests are sent with Basic auth and project_id
+	// instead of an api_key and MD5 signature.
+	ServiceAccountUser   string
+	ServiceAccountSecret string
+	// ProjectID identifies the project. When set, it is sent as the
+	// project_id parameter of every query and included in the signature.
+	ProjectID string
+	// WorkspaceID, if set, scopes queries to a workspace of the project. It
+	// must be numeric, and is sent and signed like ProjectID.
+	WorkspaceID string
+	// OAuthToken authenticates requests to the GDPR data deletion API.
+	OAuthToken string
+	// HTTPClient is used to execute requests. When nil, http.DefaultClient is
+	// used unless Proxy or TLSConfig is set.
+	HTTPClient *http.Client
+	// Proxy and TLSConfig, if either is set and HTTPClient is nil, configure a
+	// transport built from http.DefaultTransport once by NewClient and shared
+	// by all its requests. They are only honoured through NewClient, and are
+	// ignored when HTTPClient is set, so WithHTTPClient takes precedence.
+	Proxy     func(*http.Request) (*url.URL, error)
+	TLSConfig *tls.Config
+	// MaxRetries is the number of times a request is retried after a 429 or
+	// 5xx response. Zero disables retries.
+	MaxRetries int
+	// BaseBackoff is the initial delay between retries, doubled on every
+	// attempt. Defaults to DefaultBaseBackoff when zero.
+	BaseBackoff time.Duration
+	// Backoff, if set, replaces the exponential backoff built from
+	// BaseBackoff.
+	Backoff Backoff
+	// Now returns the current time, used for request expiry and event
+	// timestamps. Defaults to time.Now; set it to freeze time in tests.
+	Now func() time.Time
+	// OnRequest and OnResponse, if set, are called around every HTTP request
+	// made, including retries, for debug logging. Credentials are masked.
+	OnRequest  func(RequestInfo)
+	OnResponse func(ResponseInfo)
+	// OnMetrics, if set, is called once per call, after any retries, e.g. to
+	// update request counters and latency histograms.
+	OnMetrics func(RequestMetrics)
+	// Tracer, if set, records a span for every HTTP request.
+	Tracer Tracer
+	// MaxConcurrency, if positive, bounds the number of HTTP requests in
+	// flight at once across all calls made through a Client created with it.
+	// A streaming response holds its slot until its body is closed.
+	MaxConcurrency int
+	sem            chan struct{}
+	// MaxResponseBytes bounds the size of response bodies read into memory.
+	// Zero means DefaultMaxResponseBytes for queries and no limit for raw
+	// exports read with Do(true); negative means no limit. Streaming exports
+	// are never limited.
+	MaxResponseBytes int64
+	// MaxQueryLength is the longest query string sent in a GET; longer
+	// requests, e.g. with a large `where`, are sent as a POST with a form body
+	// instead. Defaults to DefaultMaxQueryLength; negative disables the switch.
+	MaxQueryLength int
+	// RateLimiter, if set, gates every HTTP request, including retries.
+	// Sending blocks until the limiter allows it or the context is done.
+	RateLimiter *RateLimiter
+	// UserAgent overrides DefaultUserAgent, e.g. to identify the calling service.
+	UserAgent string
+	// DryRun stops requests from being sent. Instead, every call that would
+	// send one returns a *DryRunError holding the prepared *http.Request.
+	DryRun bool
+	// DisableInsertID stops Import from generating an $insert_id for events
+	// that have none.
+	DisableInsertID bool
+	// CompressImports gzip-compresses /import payloads of at least
+	// CompressThreshold bytes, which defaults to DefaultCompressThreshold.
+	CompressImports   bool
+	CompressThreshold int
+}
+
+// EUConfig returns a Config pointed at the EU data residency hosts.
+func EUConfig(key string, secret string) Config {
+	return Config{
+		APIKey:         key,
+		APISecret:      secret,
+		QueryEndpoint:  EUEndpoint,
+		RawEndpoint:    EURawEndpoint,
+		IngestEndpoint: EUIngestEndpoint,
+	}
+}
+
+// QueryAPIConfig returns a Config sending queries to QueryAPIEndpoint with
+// the given service account and project id. Paths keep the
+// /api/2.0/<resource>/ layout, so every Get helper works unchanged.
+func QueryAPIConfig(user string, secret string, projectID string) Config {
+	return Config{
+		ServiceAccountUser:   user,
+		ServiceAccountSecret: secret,
+		ProjectID:            projectID,
+		QueryEndpoint:        QueryAPIEndpoint,
+	}
+}
+
+// usesQueryAPI reports whether queries are sent to the api.mixpanel.com
+// query API.
+func (c *Config) usesQueryAPI() bool {
+	q := c.queryEndpoint()
+	return q == QueryAPIEndpoint || q == EUQueryAPIEndpoint
+}
+
+func (c *Config) queryEndpoint() string {
+	if c.QueryEndpoint != "" {
+		return c.QueryEndpoint
+	}
+	return Endpoint
+}
+
+func (c *Config) rawEndpoint() string {
+	if c.RawEndpoint != "" {
+		return c.RawEndpoint
+	}
+	return RawEndpoint
+}
+
+func (c *Config) version() string {
+	if c.Version != "" {
+		return c.Version
+	}
+	return Version
+}
+
+// expireSeconds returns expire, or the configured default when expire is not positive.
+func (c *Config) expireSeconds(expire int) int {
+	if expire > 0 {
+		return expire
+	}
+	if c.DefaultExpire > 0 {
+		return int(c.DefaultExpire / time.Second)
+	}
+	return int(DefaultExpire / time.Second)
+}
+
+func (c *Config) now() time.Time {
+	if c.Now != nil {
+		return c.Now()
+	}
+	return time.Now()
+}
+
+func (c *Config) userAgent() string {
+	if c.UserAgent != "" {
+		return c.UserAgent
+	}
+	return DefaultUserAgent
+}
+
+func (c *Config) format() string {
+	if c.Format != "" {
+		return c.Format
+	}
+	return Format
+}
+
+// checkFormat returns an error if the configured format is not supported.
+func (c *Config) checkFormat() error {
+	switch c.format() {
+	case Format, FormatCSV:
+		return nil
+	}
+	return fmt.Errorf("mixpanel: unsupported format %q", c.Format)
+}
+
+func (c *Config) ingestEndpoint() string {
+	if c.IngestEndpoint != "" {
+		return c.IngestEndpoint
+	}
+	return IngestEndpoint
+}
+
+// usesServiceAccount reports whether requests authenticate with a service
+// account rather than the API key and secret.
+func (c *Config) usesServiceAccount() bool {
+	return c.ServiceAccountUser != ""
+}
+
+func (c *Config) maxQueryLength() int {
+	switch {
+	case c.MaxQueryLength < 0:
+		return int(^uint(0) >> 1)
+	case c.MaxQueryLength == 0:
+		return DefaultMaxQueryLength
+	}
+	return c.MaxQueryLength
+}
+
+func (c *Config) httpClient() *http.Client {
+	if c.HTTPClient != nil {
+		return c.HTTPClient
+	}
+	return http.DefaultClient
+}
+
+// newHTTPClient returns a client whose transport is a copy of
+// http.DefaultTransport with Proxy and TLSConfig applied.
+func (c *Config) newHTTPClient() *http.Client {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	if c.Proxy != nil {
+		t.Proxy = c.Proxy
+	}
+	if c.TLSConfig != nil {
+		t.TLSClientConfig = c.TLSConfig
+	}
+	return &http.Client{Transport: t}
 }
 
 // ConfigureAuth takes a path for the mixpanel key and the secret key.
-func (req *Request) ConfigureAuth(keypath string, secretpath string) {
-	req.Config = Config{
-		APIKey:    FileContents(keypath),
-		APISecret: FileContents(secretpath),
+func (req *Request) ConfigureAuth(keypath string, secretpath string) error {
+	key, err := FileContents(keypath)
+	if err != nil {
+		return err
+	}
+	secret, err := FileContents(secretpath)
+	if err != nil {
+		return err
 	}
+	req.APIKey = key
+	req.APISecret = secret
+	return nil
 }
 
 // NewRequest ...
@@ -55,12 +319,19 @@ func NewRequest() *Request {
 	return r
 }
 
+// NewRequestWithConfig returns a new Request using the given Config, e.g. one
+// returned by EUConfig.
+func NewRequestWithConfig(c Config) *Request {
+	r := NewRequest()
+	r.Config = c
+	return r
+}
+
 // GenerateSignature ...
 func (req *Request) GenerateSignature() {
-	var hash []string
 	param := make(map[string]string)
 	param["api_key"] = req.APIKey
-	param["format"] = Format
+	param["format"] = req.format()
 	param["expire"] = req.Expire
 
 	// Add the all the endpoint specific parameters
@@ -68,71 +339,156 @@ func (req *Request) GenerateSignature() {
 		param[key] = value
 	}
 
-	// Sort all the keys alphabetically and then append them to the 'to-be' hash string
-	var keys []string
+	req.Signature = Signature(param, req.APISecret)
+}
+
+// Signature returns the MD5 signature of params, which must include
+// `api_key`, `expire` and `format` but not `sig`, signed with secret.
+func Signature(param map[string]string, secret string) string {
+	// Sort all the keys alphabetically and then write them to the hash
+	keys := make([]string, 0, len(param))
 	for k := range param {
 		keys = append(keys, k)
 	}
 	sort.Strings(keys)
 
+	hasher := md5.New()
 	for _, k := range keys {
-		kv := joinKeyValue(k, param[k])
-		hash = append(hash, kv)
+		io.WriteString(hasher, k)
+		io.WriteString(hasher, "=")
+		io.WriteString(hasher, param[k])
 	}
 
 	// Append api_secret and hash
-	hash = append(hash, req.APISecret)
-	joinedhash := strings.Join(hash, "")
+	io.WriteString(hasher, secret)
+	return hex.EncodeToString(hasher.Sum(nil))
+}
 
-	req.Signature = MD5Hash(joinedhash)
+// VerifySignature reports whether expected is the signature of params signed
+// with secret, e.g. to validate a signed URL. A `sig` entry in params is ignored.
+func VerifySignature(params map[string]string, secret string, expected string) bool {
+	param := params
+	if _, ok := params["sig"]; ok {
+		param = make(map[string]string, len(params))
+		for key, value := range params {
+			if key != "sig" {
+				param[key] = value
+			}
+		}
+	}
+	sig := Signature(param, secret)
+	return subtle.ConstantTimeCompare([]byte(sig), []byte(strings.ToLower(expected))) == 1
 }
 
 // CompileURL ...
+//
+// The URL never contains the API secret, but it does carry the signature,
+// which is valid until the request expires; avoid logging it where that
+// matters. CompileURL does not validate the request; the helpers returning
+// an error, URL and the Do methods do.
 func (req *Request) CompileURL(rawflag bool) string {
-	var parts, params []string
+	var b strings.Builder
+	req.writePath(&b, rawflag)
+	b.WriteByte('?')
+	req.writeQuery(&b)
+	return b.String()
+}
+
+// URL returns the endpoint URL of the request with its signed query, or an
+// error if the format or workspace id is invalid.
+func (req *Request) URL(rawflag bool) (*url.URL, error) {
+	if err := req.checkFormat(); err != nil {
+		return nil, err
+	}
+	if err := req.checkWorkspaceID(); err != nil {
+		return nil, err
+	}
+	var b strings.Builder
+	req.writePath(&b, rawflag)
+	u, err := url.Parse(b.String())
+	if err != nil {
+		return nil, fmt.Errorf("mixpanel: invalid endpoint %q: %v", b.String(), err)
+	}
+	b.Reset()
+	req.writeQuery(&b)
+	u.RawQuery = b.String()
+	return u, nil
+}
+
+// writePath writes the endpoint URL of the request, without a query, to b.
+func (req *Request) writePath(b *strings.Builder, rawflag bool) {
+	base := req.queryEndpoint()
 	if rawflag {
-		if len(req.Method) > 0 {
-			parts = append(parts, RawEndpoint, Version, req.Endpoint, req.Method)
-		} else {
-			parts = append(parts, RawEndpoint, Version, req.Endpoint)
-		}
-	} else {
-		if len(req.Method) > 0 {
-			parts = append(parts, Endpoint, Version, req.Endpoint, req.Method)
-		} else {
-			parts = append(parts, Endpoint, Version, req.Endpoint)
+		base = req.rawEndpoint()
+	}
+	b.WriteString(strings.TrimSuffix(base, "/"))
+	for _, part := range [...]string{req.version(), req.Endpoint, req.Method} {
+		if part != "" {
+			b.WriteByte('/')
+			b.WriteString(strings.Trim(part, "/"))
 		}
 	}
-	uri := strings.Join(parts, "/")
-	uri += "/?"
+	b.WriteByte('/')
+}
 
-	for key, value := range req.Parameters {
-		kv := joinKeyValue(key, value)
-		params = append(params, kv)
+// writeQuery writes the signed, escaped query of the request to b, sorted by
+// key. It is used as the URL query for GET requests and as the form body for
+// POSTs. Values are escaped here only; the signature is computed over the raw pairs.
+func (req *Request) writeQuery(b *strings.Builder) {
+	auth := [...][2]string{
+		{"api_key", req.APIKey},
+		{"expire", req.Expire},
+		{"format", req.format()},
+		{"sig", req.Signature},
+	}
+	fixed := auth[:]
+	if req.usesServiceAccount() {
+		// Service accounts authenticate with a header; no signature is sent.
+		fixed = auth[2:3]
 	}
 
-	apikey := joinKeyValue("api_key", req.APIKey)
-	expire := joinKeyValue("expire", req.Expire)
-	format := joinKeyValue("format", Format)
-	sig := joinKeyValue("sig", req.Signature)
-	params = append(params, apikey, expire, format, sig)
-
-	url := strings.Join(params, "&")
+	keys := make([]string, 0, len(req.Parameters)+len(fixed))
+	for key := range req.Parameters {
+		keys = append(keys, key)
+	}
+	for _, kv := range fixed {
+		if _, ok := req.Parameters[kv[0]]; !ok {
+			keys = append(keys, kv[0])
+		}
+	}
+	sort.Strings(keys)
 
-	uri += url
-	return uri
+	for i, key := range keys {
+		value := req.Parameters[key]
+		for _, kv := range fixed {
+			if kv[0] == key {
+				value = kv[1]
+			}
+		}
+		if i > 0 {
+			b.WriteByte('&')
+		}
+		b.WriteString(url.QueryEscape(key))
+		b.WriteByte('=')
+		b.WriteString(url.QueryEscape(value))
+	}
 }
 
-func joinKeyValue(key string, value string) string {
-	var slice []string
-	slice = append(slice, key, value)
-	kv := strings.Join(slice, "=")
-	return kv
+// withParams returns a copy of params with the given key/value pairs added.
+func withParams(params map[string]string, kv ...string) map[string]string {
+	p := make(map[string]string, len(params)+len(kv)/2)
+	for key, value := range params {
+		p[key] = value
+	}
+	for i := 0; i+1 < len(kv); i += 2 {
+		p[kv[i]] = kv[i+1]
+	}
+	return p
 }
 
 // CalculateExpiry expire is in seconds
 func (req *Request) CalculateExpiry(expire int) string {
-	return strconv.FormatInt(time.Now().Add(time.Duration(expire)*time.Second).UTC().Unix(), 10)
+	return strconv.FormatInt(req.now().Add(time.Duration(expire)*time.Second).UTC().Unix(), 10)
 }
 
 // MD5Hash returns a md5 hash of text.
@@ -147,39 +503,267 @@ func MD5Hash(text string) string {
 //////////////
 
 // CreateRequest is the base request function that is wrapped to make more convenient request functions.
+// expire is in seconds; zero uses Config.DefaultExpire.
 func (req *Request) CreateRequest(raw bool, endpoint string, method string, expire int, params map[string]string) string {
-	NewRequest()
+	req.Parameters = make(map[string]string)
 	req.Endpoint = endpoint
 	req.Method = method
-	req.Expire = req.CalculateExpiry(expire)
+	req.Expire = req.CalculateExpiry(req.expireSeconds(expire))
+	if req.ProjectID != "" {
+		req.Parameters["project_id"] = req.ProjectID
+	}
+	if req.WorkspaceID != "" {
+		req.Parameters["workspace_id"] = req.WorkspaceID
+	}
 	for key, value := range params {
 		req.Parameters[key] = value
 	}
-	req.GenerateSignature()
+	if !req.usesServiceAccount() {
+		req.GenerateSignature()
+	}
 	url := req.CompileURL(raw)
 	return url
 }
 
-// GetEvents ...
-func (req *Request) GetEvents(params map[string]string) string {
-	return req.CreateRequest(false, "events", "", 600, params)
+// GetEvents gets event counts over time. The `type` parameter must be an
+// EventType and defaults to EventsGeneral; `interval`, if set, must be a
+// positive number of units.
+func (req *Request) GetEvents(params map[string]string) (string, error) {
+	if err := checkEvents(params); err != nil {
+		return "", err
+	}
+	return req.createChecked(false, "events", "", withEventType(params))
+}
+
+// FetchEvents prepares an events request like GetEvents and executes it,
+// returning the raw JSON response.
+func (req *Request) FetchEvents(params map[string]string) ([]byte, error) {
+	if _, err := req.GetEvents(params); err != nil {
+		return nil, err
+	}
+	return req.Do(false)
+}
+
+// Events executes an events request like FetchEvents and decodes the result.
+func (req *Request) Events(params map[string]string) (*EventsResult, error) {
+	body, err := req.FetchEvents(params)
+	if err != nil {
+		return nil, err
+	}
+	return DecodeEvents(body)
+}
+
+// GetEventsTop gets the top events of the day. The `type` parameter is
+// validated and defaulted as in GetEvents.
+func (req *Request) GetEventsTop(params map[string]string) (string, error) {
+	if err := checkEvents(params); err != nil {
+		return "", err
+	}
+	return req.createChecked(false, "events", "top", withEventType(params))
+}
+
+// GetEventsNames gets the most common event names. Optional parameters are
+// `type` and `limit`.
+func (req *Request) GetEventsNames(params map[string]string) (string, error) {
+	return req.createChecked(false, "events", "names", params)
+}
+
+// GetEventProperties gets the top properties for event. Optional parameters
+// are `limit`.
+func (req *Request) GetEventProperties(event string, params map[string]string) (string, error) {
+	return req.createChecked(false, "events/properties", "top", withParams(params, "event", event))
+}
+
+// GetEventPropertyValues gets the top values of the property name for event.
+// Optional parameters are `type`, `unit`, `interval`, `limit`, and `values`.
+func (req *Request) GetEventPropertyValues(event string, name string, params map[string]string) (string, error) {
+	return req.createChecked(false, "events/properties", "values", withParams(params, "event", event, "name", name))
 }
 
-// GetEventsTop ...
-func (req *Request) GetEventsTop(params map[string]string) string {
-	return req.CreateRequest(false, "events", "top", 600, params)
+// PropertiesNames executes GetEventProperties and returns the names of the
+// properties of event, most common first.
+func (req *Request) PropertiesNames(event string, params map[string]string) ([]string, error) {
+	if _, err := req.GetEventProperties(event, params); err != nil {
+		return nil, err
+	}
+	body, err := req.Do(false)
+	if err != nil {
+		return nil, err
+	}
+	var top map[string]struct {
+		Count float64 `json:"count"`
+	}
+	if err := decodeJSON(body, &top); err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(top))
+	for name := range top {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if top[names[i]].Count != top[names[j]].Count {
+			return top[names[i]].Count > top[names[j]].Count
+		}
+		return names[i] < names[j]
+	})
+	return names, nil
 }
 
-// GetEventsNames ...
-func (req *Request) GetEventsNames(params map[string]string) string {
-	return req.CreateRequest(false, "events", "names", 600, params)
+// GetTopPropertyValues gets the most common values of the property name of
+// event over time, with counts. Required parameters are `type` and `unit`,
+// with `from_date` and `to_date` or `interval`. Optional parameters are
+// `values`, `limit` and `format`.
+func (req *Request) GetTopPropertyValues(event string, name string, params map[string]string) (string, error) {
+	params = withParams(params, "event", event, "name", name)
+	if err := checkEvents(params); err != nil {
+		return "", err
+	}
+	return req.createChecked(false, "events/properties", "", params)
+}
+
+// TopPropertyValues executes GetTopPropertyValues and returns the values
+// with their total counts over the range, most common first.
+func (req *Request) TopPropertyValues(event string, name string, params map[string]string) ([]ValueCount, error) {
+	if _, err := req.GetTopPropertyValues(event, name, params); err != nil {
+		return nil, err
+	}
+	body, err := req.Do(false)
+	if err != nil {
+		return nil, err
+	}
+	res, err := DecodeEvents(body)
+	if err != nil {
+		return nil, err
+	}
+	return res.Totals(), nil
+}
+
+// GetSegmentation gets event data segmented and filtered by properties. Required
+// parameters are `event`, `from_date` and `to_date`. Optional parameters are
+// `on`, `where`, `unit`, `type`, and `limit`. A `type` of "bucket" also
+// requires `on` and `buckets`; see Buckets.
+func (req *Request) GetSegmentation(params map[string]string) (string, error) {
+	if err := checkBuckets(params); err != nil {
+		return "", err
+	}
+	return req.createChecked(false, "segmentation", "", params)
+}
+
+// FetchSegmentation prepares a segmentation request like GetSegmentation and
+// executes it, returning the raw JSON response.
+func (req *Request) FetchSegmentation(params map[string]string) ([]byte, error) {
+	if _, err := req.GetSegmentation(params); err != nil {
+		return nil, err
+	}
+	return req.Do(false)
+}
+
+// Segmentation executes a segmentation request like FetchSegmentation and
+// decodes the result.
+func (req *Request) Segmentation(params map[string]string) (*SegmentationResult, error) {
+	body, err := req.FetchSegmentation(params)
+	if err != nil {
+		return nil, err
+	}
+	return DecodeSegmentation(body)
+}
+
+// GetSegmentationSum sums a numeric expression over time. Required parameters
+// are `event`, `on`, `from_date` and `to_date`. Optional parameters are
+// `unit` and `where`.
+func (req *Request) GetSegmentationSum(params map[string]string) (string, error) {
+	return req.createChecked(false, "segmentation", "sum", params)
+}
+
+// GetSegmentationAverage averages a numeric expression over time. Required
+// parameters are `event`, `on`, `from_date` and `to_date`. Optional parameters
+// are `unit` and `where`.
+func (req *Request) GetSegmentationAverage(params map[string]string) (string, error) {
+	return req.createChecked(false, "segmentation", "average", params)
+}
+
+// GetRetention gets cohort retention data. Required parameters are `from_date`,
+// `to_date` and `retention_type`. Optional parameters are `born_event`, `event`,
+// `born_where`, `where`, `interval`, `unit`, and `on`.
+func (req *Request) GetRetention(params map[string]string) (string, error) {
+	return req.createChecked(false, "retention", "", params)
+}
+
+// GetAddictionRetention gets how many days in each period users performed an
+// event. Required parameters are `from_date`, `to_date`, `unit`, and
+// `addiction_unit`.
+func (req *Request) GetAddictionRetention(params map[string]string) (string, error) {
+	return req.createChecked(false, "retention", "addiction", params)
+}
+
+// GetFunnelsList gets the names and ids of the funnels defined in the project.
+func (req *Request) GetFunnelsList() (string, error) {
+	return req.createChecked(false, "funnels", "list", nil)
+}
+
+// GetFunnel gets data for a single funnel. Required parameters are `funnel_id`,
+// `from_date` and `to_date`. Optional parameters are `length`, `length_unit`,
+// `interval`, `unit`, `on`, and `where`.
+func (req *Request) GetFunnel(params map[string]string) (string, error) {
+	return req.createChecked(false, "funnels", "", params)
+}
+
+// FetchFunnel prepares a funnel request like GetFunnel and executes it,
+// returning the raw JSON response.
+func (req *Request) FetchFunnel(params map[string]string) ([]byte, error) {
+	if _, err := req.GetFunnel(params); err != nil {
+		return nil, err
+	}
+	return req.Do(false)
+}
+
+// Funnel executes a funnel request like FetchFunnel and decodes the result.
+func (req *Request) Funnel(params map[string]string) (*FunnelResult, error) {
+	body, err := req.FetchFunnel(params)
+	if err != nil {
+		return nil, err
+	}
+	return DecodeFunnel(body)
+}
+
+// GetEngage queries user profiles. Optional parameters are `where`,
+// `filter_by_cohort`, `session_id`, and `page`. Pages after the first
+// require the `session_id` returned by the first page.
+func (req *Request) GetEngage(params map[string]string) (string, error) {
+	if page, ok := params["page"]; ok && page != "0" && params["session_id"] == "" {
+		return "", fmt.Errorf("mixpanel: engage page %s requires session_id", page)
+	}
+	return req.createChecked(false, "engage", "", params)
+}
+
+// GetInsights gets the results of the saved Insights report bookmarkID.
+// Requires Config.ProjectID.
+func (req *Request) GetInsights(bookmarkID string) (string, error) {
+	if req.ProjectID == "" {
+		return "", fmt.Errorf("mixpanel: missing project_id for insights")
+	}
+	return req.createChecked(false, "insights", "", map[string]string{"bookmark_id": bookmarkID})
+}
+
+// GetActivityStream gets the events performed by distinctIDs between from and
+// to, both in the format yyyy-mm-dd. The ids are sent as a JSON array; the raw
+// JSON is signed and the query carries its escaped form.
+func (req *Request) GetActivityStream(distinctIDs []string, from string, to string) (string, error) {
+	if len(distinctIDs) == 0 {
+		return "", fmt.Errorf("mixpanel: missing required parameter %q for stream/query", "distinct_ids")
+	}
+	params := Params{"from_date": from, "to_date": to}
+	if err := params.SetJSONParam("distinct_ids", distinctIDs); err != nil {
+		return "", err
+	}
+	return req.createChecked(false, "stream", "query", params)
 }
 
 // GetRawData gets a raw data dump from mixpanel. Required parameters are `from_date`
 // and `to_date`, they are both string and in the date format yyyy-mm-dd. Optional
 // parameters are `event`, `where`, and `bucket`.
-func (req *Request) GetRawData(params map[string]string) string {
-	return req.CreateRequest(true, "export", "", 600, params)
+func (req *Request) GetRawData(params map[string]string) (string, error) {
+	return req.createChecked(true, "export", "", params)
 }
 
 ////////////
@@ -187,10 +771,25 @@ func (req *Request) GetRawData(params map[string]string) string {
 ////////////
 
 // FileContents reads out the contents of a file.
-func FileContents(filename string) string {
-	slurp, err := ioutil.ReadFile(filename)
+func FileContents(filename string) (string, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return "", fmt.Errorf("mixpanel: error reading %q: %v", filename, err)
+	}
+	defer f.Close()
+
+	contents, err := ReaderContents(f)
+	if err != nil {
+		return "", fmt.Errorf("mixpanel: error reading %q: %v", filename, err)
+	}
+	return contents, nil
+}
+
+// ReaderContents reads out the contents of r, trimmed of surrounding whitespace.
+func ReaderContents(r io.Reader) (string, error) {
+	slurp, err := ioutil.ReadAll(r)
 	if err != nil {
-		log.Fatalf("Error reading %q: %v", filename, err)
+		return "", err
 	}
-	return strings.TrimSpace(string(slurp))
+	return strings.TrimSpace(string(slurp)), nil
 }
@@ -0,0 +1,228 @@
+package mixpanel
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+const (
+	docKey    = "f0aa346688cee071cd85d857285a3464"
+	docSecret = "cdef8b34e5b1e6f2d2b4bbc0ef5b2a4c"
+)
+
+// docNow makes requests expire at 1248499222 with the default expiry.
+func docNow() time.Time { return time.Unix(1248498622, 0) }
+
+// The expected signatures are the MD5 of the sorted key=value pairs followed
+// by the secret, computed independently of this package.
+var signatureTests = []struct {
+	name     string
+	format   string
+	raw      bool
+	endpoint string
+	method   string
+	params   map[string]string
+	sig      string
+	url      string
+}{
+	{
+		name:     "events",
+		endpoint: "events",
+		params:   map[string]string{"event": `["pages"]`, "type": "unique", "unit": "hour", "interval": "24"},
+		sig:      "e0b07da731f966285bd9bf427d122c39",
+		url: "https://mixpanel.com/api/2.0/events/?api_key=" + docKey +
+			"&event=%5B%22pages%22%5D&expire=1248499222&format=json&interval=24" +
+			"&sig=e0b07da731f966285bd9bf427d122c39&type=unique&unit=hour",
+	},
+	{
+		name:     "csv export",
+		format:   FormatCSV,
+		raw:      true,
+		endpoint: "export",
+		params:   map[string]string{"from_date": "2024-01-01", "to_date": "2024-01-31"},
+		sig:      "c2d812cb4de67e10604b38b3ac421dcd",
+		url: "https://data.mixpanel.com/api/2.0/export/?api_key=" + docKey +
+			"&expire=1248499222&format=csv&from_date=2024-01-01" +
+			"&sig=c2d812cb4de67e10604b38b3ac421dcd&to_date=2024-01-31",
+	},
+}
+
+func TestSignatureVectors(t *testing.T) {
+	for _, tt := range signatureTests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := NewRequestWithConfig(Config{APIKey: docKey, APISecret: docSecret, Format: tt.format, Now: docNow})
+			uri := req.CreateRequest(tt.raw, tt.endpoint, tt.method, 0, tt.params)
+			if req.Signature != tt.sig {
+				t.Errorf("signature = %s, want %s", req.Signature, tt.sig)
+			}
+			if uri != tt.url {
+				t.Errorf("URL =\n%s\nwant\n%s", uri, tt.url)
+			}
+			if !VerifySignature(queryParams(t, uri), docSecret, tt.sig) {
+				t.Error("VerifySignature rejected the URL's own parameters")
+			}
+		})
+	}
+}
+
+func TestVerifySignature(t *testing.T) {
+	params := map[string]string{"api_key": docKey, "expire": "1248499222", "format": "json",
+		"event": `["pages"]`, "type": "unique", "unit": "hour", "interval": "24"}
+	const sig = "e0b07da731f966285bd9bf427d122c39"
+	tests := []struct {
+		name   string
+		secret string
+		sig    string
+		extra  map[string]string
+		want   bool
+	}{
+		{"valid", docSecret, sig, nil, true},
+		{"upper case", docSecret, "E0B07DA731F966285BD9BF427D122C39", nil, true},
+		{"sig ignored", docSecret, sig, map[string]string{"sig": "ignored"}, true},
+		{"wrong secret", "other", sig, nil, false},
+		{"tampered", docSecret, sig, map[string]string{"unit": "day"}, false},
+	}
+	for _, tt := range tests {
+		p := withParams(params)
+		for key, value := range tt.extra {
+			p[key] = value
+		}
+		if got := VerifySignature(p, tt.secret, tt.sig); got != tt.want {
+			t.Errorf("%s: VerifySignature = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestQueryEscaping(t *testing.T) {
+	where := `properties["$city"]=="New York" & café`
+	req := NewRequestWithConfig(Config{APIKey: "key", APISecret: "secret", Now: docNow})
+	uri := req.CreateRequest(false, "segmentation", "", 0, map[string]string{
+		"event": "Signup", "from_date": "2024-01-01", "to_date": "2024-01-31", "where": where,
+	})
+	u, err := req.URL(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.String() != uri {
+		t.Errorf("URL and CompileURL differ:\n%s\n%s", u, uri)
+	}
+	for _, c := range []string{" ", `"`, "é", "$", "[", "&properties"} {
+		if strings.Contains(u.RawQuery, c) {
+			t.Errorf("query contains unescaped %q: %s", c, u.RawQuery)
+		}
+	}
+	q := queryParams(t, uri)
+	if q["where"] != where {
+		t.Errorf("where round-trips as %q, want %q", q["where"], where)
+	}
+	// The raw, unescaped value is signed.
+	if want := "486c4e6ade6729b08fd0c06d44f0e143"; req.Signature != want {
+		t.Errorf("signature = %s, want %s", req.Signature, want)
+	}
+}
+
+func TestRequestReuse(t *testing.T) {
+	req := NewRequestWithConfig(Config{APIKey: "key", APISecret: "secret", Now: docNow})
+	req.CreateRequest(false, "events", "", 0, map[string]string{"event": `["a"]`, "unit": "day", "interval": "7"})
+	uri := req.CreateRequest(true, "export", "", 0, map[string]string{"from_date": "2024-01-01", "to_date": "2024-01-31"})
+
+	fresh := NewRequestWithConfig(Config{APIKey: "key", APISecret: "secret", Now: docNow})
+	want := fresh.CreateRequest(true, "export", "", 0, map[string]string{"from_date": "2024-01-01", "to_date": "2024-01-31"})
+	if uri != want {
+		t.Errorf("reused request URL =\n%s\nwant\n%s", uri, want)
+	}
+	for _, key := range []string{"event", "unit", "interval"} {
+		if _, ok := req.Parameters[key]; ok {
+			t.Errorf("parameter %q leaked from the previous call", key)
+		}
+	}
+	if req.Signature != fresh.Signature {
+		t.Error("reused request has a different signature")
+	}
+}
+
+func TestProjectID(t *testing.T) {
+	req := NewRequestWithConfig(Config{APIKey: "key", APISecret: "secret", ProjectID: "123", Now: docNow})
+	uri, err := req.GetSegmentation(map[string]string{"event": "Signup", "from_date": "2024-01-01", "to_date": "2024-01-31"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	q := queryParams(t, uri)
+	if q["project_id"] != "123" {
+		t.Errorf("URL project_id = %q, want 123", q["project_id"])
+	}
+	if !VerifySignature(q, "secret", req.Signature) {
+		t.Error("signature does not cover the URL parameters")
+	}
+	delete(q, "project_id")
+	if VerifySignature(q, "secret", req.Signature) {
+		t.Error("project_id is not part of the signature")
+	}
+}
+
+func TestFrozenClock(t *testing.T) {
+	cfg := Config{APIKey: "key", APISecret: "secret", Now: func() time.Time { return time.Unix(1600000000, 0) }}
+	params := map[string]string{"event": "a b&c"}
+	first := NewRequestWithConfig(cfg)
+	uri := first.CreateRequest(false, "events", "", 0, params)
+	if first.Expire != "1600000600" {
+		t.Errorf("expire = %s, want 1600000600", first.Expire)
+	}
+	// md5("api_key=keyevent=a b&cexpire=1600000600format=jsonsecret")
+	if want := "77141f5437ff4eca338322a02f847f7c"; first.Signature != want {
+		t.Errorf("signature = %s, want %s", first.Signature, want)
+	}
+	second := NewRequestWithConfig(cfg)
+	if again := second.CreateRequest(false, "events", "", 0, params); again != uri {
+		t.Errorf("URL changed under a frozen clock:\n%s\n%s", uri, again)
+	}
+}
+
+// joinedSignature is the signature as computed before it was streamed into
+// the hash: the sorted pairs joined into one string.
+func joinedSignature(param map[string]string, secret string) string {
+	keys := make([]string, 0, len(param))
+	for k := range param {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	pairs := make([]string, len(keys))
+	for i, k := range keys {
+		pairs[i] = k + "=" + param[k]
+	}
+	sum := md5.Sum([]byte(strings.Join(pairs, "") + secret))
+	return hex.EncodeToString(sum[:])
+}
+
+func TestSignatureMatchesJoined(t *testing.T) {
+	tests := []map[string]string{
+		{},
+		{"api_key": docKey, "expire": "1248499222", "format": "json"},
+		{"api_key": docKey, "event": `["A","B"]`, "where": `properties["$city"]=="New York" & café`},
+		{"script": strings.Repeat(`function main() { return Events({}) }`, 1000), "": "empty key"},
+	}
+	for i, param := range tests {
+		if got, want := Signature(param, docSecret), joinedSignature(param, docSecret); got != want {
+			t.Errorf("%d: signature = %s, want %s", i, got, want)
+		}
+	}
+}
+
+func BenchmarkCompileURL(b *testing.B) {
+	req := NewRequestWithConfig(Config{APIKey: docKey, APISecret: docSecret, Now: docNow})
+	req.CreateRequest(false, "segmentation", "", 0, map[string]string{
+		"event":     "Signup",
+		"from_date": "2024-01-01",
+		"to_date":   "2024-01-31",
+		"where":     `properties["$city"]=="New York"`,
+		"unit":      "day",
+	})
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		req.CompileURL(false)
+	}
+}
@@ -0,0 +1,58 @@
+package mixpanel
+
+import (
+	"encoding/json"
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Params is a set of request parameters. It can be passed wherever a
+// map[string]string of parameters is expected.
+type Params map[string]string
+
+// SetJSONParam sets key to the JSON encoding of v, for parameters such as
+// `event` or `distinct_ids` that take a JSON array. The encoded string is
+// used unchanged in the signature and escaped only in the URL.
+func (p Params) SetJSONParam(key string, v interface{}) error {
+	encoded, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	p[key] = string(encoded)
+	return nil
+}
+
+// SegmentationBucket is the `type` of a segmentation request that buckets a
+// numeric `on` property into ranges.
+const SegmentationBucket = "bucket"
+
+// Buckets formats bounds as the `buckets` parameter of a bucketed
+// segmentation request, e.g. Buckets([]float64{0, 10, 60}).
+func Buckets(bounds []float64) string {
+	parts := make([]string, len(bounds))
+	for i, b := range bounds {
+		parts[i] = strconv.FormatFloat(b, 'f', -1, 64)
+	}
+	return "[" + strings.Join(parts, ",") + "]"
+}
+
+// checkBuckets validates the `buckets` parameter of a segmentation request
+// with `type` bucket: it must be a non-empty, ascending list of numbers.
+func checkBuckets(params map[string]string) error {
+	if params["type"] != SegmentationBucket {
+		return nil
+	}
+	if params["on"] == "" {
+		return errors.New(`mixpanel: missing required parameter "on" for bucketed segmentation`)
+	}
+	var bounds []float64
+	if err := json.Unmarshal([]byte(params["buckets"]), &bounds); err != nil || len(bounds) == 0 {
+		return errors.New(`mixpanel: "buckets" must be a non-empty JSON array of numbers`)
+	}
+	if !sort.Float64sAreSorted(bounds) {
+		return errors.New(`mixpanel: "buckets" must be in ascending order`)
+	}
+	return nil
+}
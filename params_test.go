@@ -0,0 +1,35 @@
+package mixpanel
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSegmentationJSONEvent(t *testing.T) {
+	params := Params{"from_date": "2024-01-01", "to_date": "2024-01-31"}
+	if err := params.SetJSONParam("event", []string{"Signup", "Login"}); err != nil {
+		t.Fatal(err)
+	}
+	if params["event"] != `["Signup","Login"]` {
+		t.Fatalf("event = %s", params["event"])
+	}
+	req := NewRequestWithConfig(Config{APIKey: docKey, APISecret: docSecret, Now: docNow})
+	uri, err := req.GetSegmentation(params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// md5 of the sorted pairs, with event=["Signup","Login"] unescaped, and the secret.
+	if want := "1796fc7d24ceb51c82e77cb3588a97c4"; req.Signature != want {
+		t.Errorf("signature = %s, want %s", req.Signature, want)
+	}
+	if want := "&event=%5B%22Signup%22%2C%22Login%22%5D&"; !strings.Contains(uri, want) {
+		t.Errorf("URL %s does not contain %s", uri, want)
+	}
+	query := queryParams(t, uri)
+	if query["event"] != params["event"] {
+		t.Errorf("event in URL = %s, want %s", query["event"], params["event"])
+	}
+	if !VerifySignature(query, docSecret, req.Signature) {
+		t.Error("signature does not cover the event sent in the URL")
+	}
+}
@@ -0,0 +1,136 @@
+package mixpanel
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// pipelineStates lists the job states reported by the pipeline status
+// endpoint.
+var pipelineStates = []string{"pending", "running", "retried", "succeeded", "failed", "canceled"}
+
+// PipelineStatus is the progress of a data pipeline: the number of its jobs
+// in each state.
+type PipelineStatus struct {
+	Name   string
+	Counts map[string]int
+}
+
+// Done reports whether the pipeline has jobs and none of them is pending,
+// running or being retried.
+func (s *PipelineStatus) Done() bool {
+	total := 0
+	for _, n := range s.Counts {
+		total += n
+	}
+	return total > 0 && s.Counts["pending"]+s.Counts["running"]+s.Counts["retried"] == 0
+}
+
+// Failed reports whether any job of the pipeline failed or was canceled.
+func (s *PipelineStatus) Failed() bool {
+	return s.Counts["failed"]+s.Counts["canceled"] > 0
+}
+
+// CreatePipeline creates a data pipeline exporting to a warehouse or bucket
+// and returns the names of the pipelines created, which identify them in
+// GetPipelineStatus. Required parameters are `type` and the destination's
+// parameters; see the Data Pipelines API. Config.ProjectID is added if set.
+func (c *Client) CreatePipeline(params map[string]string) ([]string, error) {
+	return c.CreatePipelineContext(context.Background(), params)
+}
+
+// CreatePipelineContext is CreatePipeline with a context.
+func (c *Client) CreatePipelineContext(ctx context.Context, params map[string]string) ([]string, error) {
+	if params["type"] == "" {
+		return nil, errors.New(`mixpanel: missing required parameter "type" for pipeline`)
+	}
+	form := make(url.Values, len(params)+1)
+	for key, value := range params {
+		form.Set(key, value)
+	}
+	if c.config.ProjectID != "" {
+		form.Set("project_id", c.config.ProjectID)
+	}
+	resp, err := c.config.sendBody(ctx, "pipelines/create", http.MethodPost, c.config.pipelineURL("create"), []byte(form.Encode()),
+		c.config.basicAuthHeader("application/x-www-form-urlencoded"))
+	if err != nil {
+		return nil, err
+	}
+	body, err := c.config.readBody(ctx, resp)
+	if err != nil {
+		return nil, err
+	}
+	var out struct {
+		PipelineNames []string `json:"pipeline_names"`
+	}
+	if err := decodeJSON(body, &out); err != nil {
+		return nil, err
+	}
+	return out.PipelineNames, nil
+}
+
+// GetPipelineStatus returns the progress of the pipeline name.
+func (c *Client) GetPipelineStatus(name string) (*PipelineStatus, error) {
+	return c.GetPipelineStatusContext(context.Background(), name)
+}
+
+// GetPipelineStatusContext is GetPipelineStatus with a context.
+func (c *Client) GetPipelineStatusContext(ctx context.Context, name string) (*PipelineStatus, error) {
+	if name == "" {
+		return nil, errors.New("mixpanel: missing pipeline name")
+	}
+	q := url.Values{"name": {name}, "summary": {"true"}}
+	if c.config.ProjectID != "" {
+		q.Set("project_id", c.config.ProjectID)
+	}
+	var out map[string]json.RawMessage
+	if err := c.config.sendJSON(ctx, "pipelines/status", http.MethodGet, c.config.pipelineURL("status")+"?"+q.Encode(), c.config.basicAuthHeader(""), nil, &out); err != nil {
+		return nil, err
+	}
+	if nested, ok := out["status"]; ok {
+		if err := json.Unmarshal(nested, &out); err != nil {
+			return nil, err
+		}
+	}
+	status := &PipelineStatus{Name: name, Counts: make(map[string]int)}
+	for _, state := range pipelineStates {
+		raw, ok := out[state]
+		if !ok {
+			continue
+		}
+		// The summary holds counts; the full status lists the jobs.
+		var n int
+		if err := json.Unmarshal(raw, &n); err != nil {
+			var jobs []json.RawMessage
+			if err := json.Unmarshal(raw, &jobs); err != nil {
+				return nil, err
+			}
+			n = len(jobs)
+		}
+		status.Counts[state] = n
+	}
+	return status, nil
+}
+
+// WaitPipeline polls the status of the pipeline name every interval until it
+// is Done or ctx is done, and returns the last status.
+func (c *Client) WaitPipeline(ctx context.Context, name string, interval time.Duration) (*PipelineStatus, error) {
+	for {
+		status, err := c.GetPipelineStatusContext(ctx, name)
+		if err != nil || status.Done() {
+			return status, err
+		}
+		if err := sleepContext(ctx, interval); err != nil {
+			return status, err
+		}
+	}
+}
+
+// pipelineURL returns the URL of the pipelines API method.
+func (c *Config) pipelineURL(method string) string {
+	return c.rawEndpoint() + "/" + c.version() + "/nessie/pipeline/" + method
+}
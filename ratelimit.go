@@ -0,0 +1,63 @@
+package mixpanel
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// RateLimiter is a token bucket limiting how often requests are sent. It is
+// safe for concurrent use and may be shared by several Configs and Clients.
+type RateLimiter struct {
+	mu       sync.Mutex
+	interval time.Duration
+	burst    float64
+	tokens   float64
+	last     time.Time
+}
+
+// NewRateLimiter returns a RateLimiter allowing perSecond requests per second
+// on average and bursts of up to burst requests. A burst below one is treated
+// as one. If perSecond is not positive, NewRateLimiter returns nil, meaning
+// no limit.
+func NewRateLimiter(perSecond float64, burst int) *RateLimiter {
+	if perSecond <= 0 {
+		return nil
+	}
+	if burst < 1 {
+		burst = 1
+	}
+	return &RateLimiter{
+		interval: time.Duration(float64(time.Second) / perSecond),
+		burst:    float64(burst),
+		tokens:   float64(burst),
+	}
+}
+
+// reserve takes a token, returning how long the caller must wait before it
+// may proceed.
+func (l *RateLimiter) reserve(now time.Time) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if !l.last.IsZero() {
+		l.tokens += float64(now.Sub(l.last)) / float64(l.interval)
+		if l.tokens > l.burst {
+			l.tokens = l.burst
+		}
+	}
+	l.last = now
+	l.tokens--
+	if l.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-l.tokens * float64(l.interval))
+}
+
+// Wait blocks until a request may be sent or ctx is done. A token taken by a
+// cancelled Wait is not returned to the bucket.
+func (l *RateLimiter) Wait(ctx context.Context) error {
+	if d := l.reserve(time.Now()); d > 0 {
+		return sleepContext(ctx, d)
+	}
+	return ctx.Err()
+}
@@ -0,0 +1,45 @@
+package mixpanel
+
+import "fmt"
+
+// mask hides a credential, keeping only whether it is set.
+func mask(s string) string {
+	if s == "" {
+		return ""
+	}
+	return "****"
+}
+
+// String implements fmt.Stringer. Credentials are masked so that a Config can
+// be logged safely.
+func (c Config) String() string {
+	return fmt.Sprintf("Config{APIKey:%s APISecret:%s ServiceAccountUser:%s ServiceAccountSecret:%s OAuthToken:%s Token:%s ProjectID:%s QueryEndpoint:%s RawEndpoint:%s IngestEndpoint:%s}",
+		mask(c.APIKey), mask(c.APISecret), c.ServiceAccountUser, mask(c.ServiceAccountSecret), mask(c.OAuthToken),
+		c.Token, c.ProjectID, c.queryEndpoint(), c.rawEndpoint(), c.ingestEndpoint())
+}
+
+// GoString implements fmt.GoStringer so that %#v is masked as well.
+func (c Config) GoString() string {
+	return c.String()
+}
+
+// String implements fmt.Stringer. The Config is masked as in Config.String.
+func (req Request) String() string {
+	return fmt.Sprintf("Request{Endpoint:%s Method:%s Parameters:%v Expire:%s Signature:%s %s}",
+		req.Endpoint, req.Method, req.Parameters, req.Expire, req.Signature, req.Config)
+}
+
+// GoString implements fmt.GoStringer so that %#v is masked as well.
+func (req Request) GoString() string {
+	return req.String()
+}
+
+// String implements fmt.Stringer. The Config is masked as in Config.String.
+func (c *Client) String() string {
+	return "Client{" + c.config.String() + "}"
+}
+
+// GoString implements fmt.GoStringer so that %#v is masked as well.
+func (c *Client) GoString() string {
+	return c.String()
+}
@@ -0,0 +1,34 @@
+package mixpanel
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestRedaction(t *testing.T) {
+	cfg := Config{
+		APIKey:               "apikey-1234",
+		APISecret:            "apisecret-5678",
+		ServiceAccountSecret: "sasecret-9012",
+		OAuthToken:           "oauth-3456",
+		Now:                  docNow,
+	}
+	req := NewRequestWithConfig(cfg)
+	c := NewClient(WithConfig(cfg))
+	uri := req.CreateRequest(false, "events", "", 0, map[string]string{"event": `["a"]`})
+	secrets := []string{cfg.APIKey, cfg.APISecret, cfg.ServiceAccountSecret, cfg.OAuthToken}
+	for _, verb := range []string{"%v", "%+v", "%#v", "%s"} {
+		for _, v := range []interface{}{cfg, &cfg, *req, req, c} {
+			out := fmt.Sprintf(verb, v)
+			for _, secret := range secrets {
+				if strings.Contains(out, secret) {
+					t.Errorf("%s of %T contains %q: %s", verb, v, secret, out)
+				}
+			}
+		}
+	}
+	if strings.Contains(uri, cfg.APISecret) {
+		t.Errorf("URL contains the API secret: %s", uri)
+	}
+}
@@ -0,0 +1,133 @@
+package mixpanel
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// SegmentationResult is the response of the segmentation endpoints. Series
+// holds the dates of the report and Values maps each segment to its value on
+// each date.
+type SegmentationResult struct {
+	LegendSize int `json:"legend_size"`
+	Data       struct {
+		Series []string                      `json:"series"`
+		Values map[string]map[string]float64 `json:"values"`
+	} `json:"data"`
+}
+
+// Segments returns the segments of the result in order. Bucket labels such as
+// "10 - 60" are ordered by their lower bound, other segments by name.
+func (r *SegmentationResult) Segments() []string {
+	keys := make([]string, 0, len(r.Data.Values))
+	for key := range r.Data.Values {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		a, aok := bucketStart(keys[i])
+		b, bok := bucketStart(keys[j])
+		if aok && bok && a != b {
+			return a < b
+		}
+		if aok != bok {
+			return aok
+		}
+		return keys[i] < keys[j]
+	})
+	return keys
+}
+
+// bucketStart parses the lower bound of a bucket label.
+func bucketStart(label string) (float64, bool) {
+	if i := strings.Index(label, " - "); i > 0 {
+		label = label[:i]
+	}
+	f, err := strconv.ParseFloat(strings.TrimSpace(label), 64)
+	return f, err == nil
+}
+
+// DecodeSegmentation decodes the response of a segmentation request.
+func DecodeSegmentation(body []byte) (*SegmentationResult, error) {
+	var res SegmentationResult
+	if err := decodeJSON(body, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// EventsResult is the response of the events endpoint, and of the
+// events/properties endpoint. Series holds the dates of the report and Values
+// maps each event, or property value, to its value on each date.
+type EventsResult struct {
+	Data struct {
+		Series []string                      `json:"series"`
+		Values map[string]map[string]float64 `json:"values"`
+	} `json:"data"`
+	LegendSize int `json:"legend_size"`
+}
+
+// ValueCount is the total of a series of a report.
+type ValueCount struct {
+	Value string
+	Count float64
+}
+
+// Totals returns the sum of each series of the result over all dates,
+// largest first.
+func (r *EventsResult) Totals() []ValueCount {
+	totals := make([]ValueCount, 0, len(r.Data.Values))
+	for value, counts := range r.Data.Values {
+		vc := ValueCount{Value: value}
+		for _, n := range counts {
+			vc.Count += n
+		}
+		totals = append(totals, vc)
+	}
+	sort.Slice(totals, func(i, j int) bool {
+		if totals[i].Count != totals[j].Count {
+			return totals[i].Count > totals[j].Count
+		}
+		return totals[i].Value < totals[j].Value
+	})
+	return totals
+}
+
+// DecodeEvents decodes the response of an events request.
+func DecodeEvents(body []byte) (*EventsResult, error) {
+	var res EventsResult
+	if err := decodeJSON(body, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// FunnelStep is a single step of a funnel on one date.
+type FunnelStep struct {
+	Count            int     `json:"count"`
+	StepConvRatio    float64 `json:"step_conv_ratio"`
+	OverallConvRatio float64 `json:"overall_conv_ratio"`
+	AvgTime          float64 `json:"avg_time"`
+	Event            string  `json:"event"`
+	Goal             string  `json:"goal"`
+}
+
+// FunnelResult is the response of the funnels endpoint. Data maps each date
+// in Meta.Dates to the funnel's steps on that date.
+type FunnelResult struct {
+	Meta struct {
+		Dates []string `json:"dates"`
+	} `json:"meta"`
+	Data map[string]struct {
+		Steps []FunnelStep `json:"steps"`
+	} `json:"data"`
+}
+
+// DecodeFunnel decodes the response of a funnel request.
+func DecodeFunnel(body []byte) (*FunnelResult, error) {
+	var res FunnelResult
+	if err := decodeJSON(body, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
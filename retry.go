@@ -0,0 +1,91 @@
+package mixpanel
+
+import (
+	"context"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultBaseBackoff is the initial retry delay used when Config.BaseBackoff is zero.
+const DefaultBaseBackoff = 500 * time.Millisecond
+
+// retryable reports whether a response with the given status should be retried.
+func retryable(status int) bool {
+	return status == http.StatusTooManyRequests || status >= 500
+}
+
+// Backoff computes the delay before a retry. A Retry-After header sent by
+// Mixpanel takes precedence over it.
+type Backoff interface {
+	// NextDelay returns the delay before retry number attempt, starting at
+	// zero.
+	NextDelay(attempt int) time.Duration
+}
+
+// ExponentialBackoff is the default Backoff: Base doubled per attempt, with
+// jitter in the upper half of the window. Base defaults to DefaultBaseBackoff.
+type ExponentialBackoff struct {
+	Base time.Duration
+}
+
+// NextDelay implements Backoff.
+func (b ExponentialBackoff) NextDelay(attempt int) time.Duration {
+	base := b.Base
+	if base <= 0 {
+		base = DefaultBaseBackoff
+	}
+	d := base << uint(attempt)
+	if d <= 0 {
+		// Overflowed; cap at the largest representable delay.
+		d = time.Duration(1<<63 - 1)
+	}
+	half := d / 2
+	return half + time.Duration(rand.Int63n(int64(half)+1))
+}
+
+// backoff returns the delay before retry number attempt from Config.Backoff,
+// or an ExponentialBackoff from BaseBackoff when it is nil.
+func (c *Config) backoff(attempt int) time.Duration {
+	if c.Backoff != nil {
+		return c.Backoff.NextDelay(attempt)
+	}
+	return ExponentialBackoff{Base: c.BaseBackoff}.NextDelay(attempt)
+}
+
+// retryAfter parses a Retry-After header in either its delta-seconds or
+// HTTP-date form, returning the delay relative to now.
+func retryAfter(h http.Header, now time.Time) (time.Duration, bool) {
+	v := strings.TrimSpace(h.Get("Retry-After"))
+	if v == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(v); err == nil {
+		if secs < 0 {
+			return 0, false
+		}
+		return time.Duration(secs) * time.Second, true
+	}
+	t, err := http.ParseTime(v)
+	if err != nil {
+		return 0, false
+	}
+	if d := t.Sub(now); d > 0 {
+		return d, true
+	}
+	return 0, true
+}
+
+// sleepContext waits for d or until ctx is done, whichever comes first.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
@@ -0,0 +1,112 @@
+package mixpanel
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// roundTripFunc is an http.RoundTripper backed by a function.
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) { return f(r) }
+
+// fakeResponses returns a client answering with statuses in turn, and a
+// pointer to the number of requests made.
+func fakeResponses(statuses ...int) (*http.Client, *int) {
+	calls := 0
+	return &http.Client{Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		status := statuses[calls]
+		calls++
+		return &http.Response{
+			StatusCode: status,
+			Header:     make(http.Header),
+			Body:       ioutil.NopCloser(strings.NewReader(`{}`)),
+			Request:    r,
+		}, nil
+	})}, &calls
+}
+
+func TestRetry(t *testing.T) {
+	hc, calls := fakeResponses(http.StatusTooManyRequests, http.StatusTooManyRequests, http.StatusOK)
+	req := NewRequestWithConfig(Config{APIKey: "key", APISecret: "secret", HTTPClient: hc,
+		MaxRetries: 2, BaseBackoff: time.Millisecond})
+	req.CreateRequest(false, "funnels", "list", 0, nil)
+	if _, err := req.Do(false); err != nil {
+		t.Fatal(err)
+	}
+	if *calls != 3 {
+		t.Errorf("made %d requests, want 3", *calls)
+	}
+}
+
+func TestRetryExhausted(t *testing.T) {
+	hc, calls := fakeResponses(http.StatusServiceUnavailable, http.StatusServiceUnavailable, http.StatusOK)
+	req := NewRequestWithConfig(Config{APIKey: "key", APISecret: "secret", HTTPClient: hc,
+		MaxRetries: 1, BaseBackoff: time.Millisecond})
+	req.CreateRequest(false, "funnels", "list", 0, nil)
+	_, err := req.Do(false)
+	if apiErr, ok := err.(*APIError); !ok || apiErr.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("error = %v, want a 503 *APIError", err)
+	}
+	if *calls != 2 {
+		t.Errorf("made %d requests, want 2", *calls)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		value string
+		want  time.Duration
+		ok    bool
+	}{
+		{"", 0, false},
+		{"120", 120 * time.Second, true},
+		{" 3 ", 3 * time.Second, true},
+		{"-1", 0, false},
+		{"Mon, 01 Jan 2024 12:00:30 GMT", 30 * time.Second, true},
+		{"Mon, 01 Jan 2024 11:59:00 GMT", 0, true},
+		{"soon", 0, false},
+	}
+	for _, tt := range tests {
+		h := http.Header{}
+		if tt.value != "" {
+			h.Set("Retry-After", tt.value)
+		}
+		got, ok := retryAfter(h, now)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("Retry-After %q = %v, %v; want %v, %v", tt.value, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestRetryAfterOverridesBackoff(t *testing.T) {
+	for _, value := range []string{"0", time.Now().Add(-time.Minute).UTC().Format(http.TimeFormat)} {
+		calls := 0
+		hc := &http.Client{Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+			calls++
+			resp := &http.Response{StatusCode: http.StatusOK, Header: make(http.Header),
+				Body: ioutil.NopCloser(strings.NewReader(`{}`)), Request: r}
+			if calls == 1 {
+				resp.StatusCode = http.StatusTooManyRequests
+				resp.Header.Set("Retry-After", value)
+			}
+			return resp, nil
+		})}
+		// With an hour of backoff, the retry only happens in time if
+		// Retry-After is honoured.
+		req := NewRequestWithConfig(Config{APIKey: "key", APISecret: "secret", HTTPClient: hc,
+			MaxRetries: 1, BaseBackoff: time.Hour})
+		req.CreateRequest(false, "funnels", "list", 0, nil)
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		_, err := req.DoContext(ctx, false)
+		cancel()
+		if err != nil || calls != 2 {
+			t.Errorf("Retry-After %q: err = %v after %d requests", value, err, calls)
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package mixpanel
+
+// RevenuePeriod is the revenue of one period of a revenue report.
+type RevenuePeriod struct {
+	Amount    float64 `json:"amount"`
+	Count     int     `json:"count"`
+	PaidCount int     `json:"paid_count"`
+}
+
+// RevenueResult is the response of the revenue endpoint. Results maps each
+// period, and "$overall" for the whole range, to its revenue.
+type RevenueResult struct {
+	ComputedAt string                   `json:"computed_at"`
+	Results    map[string]RevenuePeriod `json:"results"`
+}
+
+// GetRevenue gets the revenue tracked on profiles with `$transactions`.
+// Required parameters are `from_date` and `to_date`. Optional parameters are
+// `unit`, `type` and `on`.
+func (req *Request) GetRevenue(params map[string]string) (string, error) {
+	return req.createChecked(false, "engage", "revenue", params)
+}
+
+// Revenue executes GetRevenue and decodes the result.
+func (req *Request) Revenue(params map[string]string) (*RevenueResult, error) {
+	if _, err := req.GetRevenue(params); err != nil {
+		return nil, err
+	}
+	body, err := req.Do(false)
+	if err != nil {
+		return nil, err
+	}
+	var res RevenueResult
+	if err := decodeJSON(body, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
@@ -0,0 +1,36 @@
+package mixpanel
+
+import (
+	"context"
+	"io"
+	"sync"
+)
+
+// acquire takes a slot of the Client's concurrency limit, blocking until one
+// is free or ctx is done, and returns the function releasing it. Without a
+// limit it returns immediately.
+func (c *Config) acquire(ctx context.Context) (func(), error) {
+	if c.sem == nil {
+		return func() {}, nil
+	}
+	select {
+	case c.sem <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	var once sync.Once
+	return func() { once.Do(func() { <-c.sem }) }, nil
+}
+
+// releaseBody releases a concurrency slot when the response body is closed,
+// so that a streaming export holds its slot until it is read.
+type releaseBody struct {
+	io.ReadCloser
+	release func()
+}
+
+func (b releaseBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.release()
+	return err
+}
@@ -0,0 +1,23 @@
+package mixpanel
+
+import (
+	"net/http"
+	"net/http/httptest"
+)
+
+// NewTestServer starts an httptest.Server serving handler and returns a
+// Client whose query, raw export and ingestion endpoints all point at it,
+// along with a func that shuts the server down. opts are applied after the
+// endpoints, with test credentials set by default. It is meant for testing
+// code that uses this package without reaching Mixpanel.
+func NewTestServer(handler http.HandlerFunc, opts ...Option) (*Client, func()) {
+	srv := httptest.NewServer(handler)
+	base := []Option{
+		WithCredentials("test-key", "test-secret"),
+		WithToken("test-token"),
+		WithHTTPClient(srv.Client()),
+		WithEndpoint(srv.URL, srv.URL),
+		WithIngestEndpoint(srv.URL),
+	}
+	return NewClient(append(base, opts...)...), srv.Close
+}
@@ -0,0 +1,89 @@
+package mixpanel
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// Unit is the time unit of a report, passed as its `unit` parameter.
+type Unit string
+
+// Units accepted by the query endpoints.
+const (
+	UnitMinute Unit = "minute"
+	UnitHour   Unit = "hour"
+	UnitDay    Unit = "day"
+	UnitWeek   Unit = "week"
+	UnitMonth  Unit = "month"
+)
+
+// EventType is the `type` parameter of the events endpoints.
+type EventType string
+
+// Event types accepted by the events endpoints.
+const (
+	EventsGeneral EventType = "general"
+	EventsUnique  EventType = "unique"
+	EventsAverage EventType = "average"
+)
+
+// SetEventType sets the `type` parameter of an events request, returning an
+// error if t is not one of the EventType constants.
+func (p Params) SetEventType(t EventType) error {
+	switch t {
+	case EventsGeneral, EventsUnique, EventsAverage:
+		p["type"] = string(t)
+		return nil
+	}
+	return fmt.Errorf("mixpanel: invalid events type %q", t)
+}
+
+// withEventType returns params with `type` defaulting to EventsGeneral.
+func withEventType(params map[string]string) map[string]string {
+	if params["type"] != "" {
+		return params
+	}
+	return withParams(params, "type", string(EventsGeneral))
+}
+
+// endpointUnits lists the units accepted by each request, keyed like
+// requiredParams. Requests not listed accept any Unit.
+var endpointUnits = map[string][]Unit{
+	"events":                   {UnitMinute, UnitHour, UnitDay, UnitWeek, UnitMonth},
+	"events/properties":        {UnitMinute, UnitHour, UnitDay, UnitWeek, UnitMonth},
+	"events/properties/values": {UnitMinute, UnitHour, UnitDay, UnitWeek, UnitMonth},
+	"segmentation":             {UnitMinute, UnitHour, UnitDay, UnitMonth},
+	"segmentation/sum":         {UnitHour, UnitDay},
+	"segmentation/average":     {UnitHour, UnitDay},
+	"retention":                {UnitDay, UnitWeek, UnitMonth},
+	"retention/addiction":      {UnitDay, UnitWeek, UnitMonth},
+	"funnels":                  {UnitDay, UnitWeek, UnitMonth},
+	"engage/revenue":           {UnitDay, UnitWeek, UnitMonth},
+}
+
+// SetUnit sets the `unit` parameter for the request to endpoint and method,
+// returning an error if that request does not accept u.
+func (p Params) SetUnit(endpoint string, method string, u Unit) error {
+	name := requestName(endpoint, method)
+	if units, ok := endpointUnits[name]; ok {
+		valid := false
+		for _, unit := range units {
+			valid = valid || unit == u
+		}
+		if !valid {
+			return fmt.Errorf("mixpanel: unit %q is not supported by %s", u, name)
+		}
+	}
+	p["unit"] = string(u)
+	return nil
+}
+
+// SetInterval sets the `interval` parameter, the number of units to report
+// ending today, in place of `from_date` and `to_date`.
+func (p Params) SetInterval(n int) error {
+	if n < 1 {
+		return fmt.Errorf("mixpanel: interval must be positive, got %d", n)
+	}
+	p["interval"] = strconv.Itoa(n)
+	return nil
+}
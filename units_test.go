@@ -0,0 +1,79 @@
+package mixpanel
+
+import (
+	"net/url"
+	"testing"
+	"time"
+)
+
+func frozenConfig() Config {
+	return Config{
+		APIKey:    "key",
+		APISecret: "secret",
+		Now:       func() time.Time { return time.Unix(1700000000, 0) },
+	}
+}
+
+// queryParams returns the single-valued parameters of the query of rawURL.
+func queryParams(t *testing.T, rawURL string) map[string]string {
+	t.Helper()
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	params := make(map[string]string)
+	for key, values := range u.Query() {
+		params[key] = values[0]
+	}
+	return params
+}
+
+func TestEventsType(t *testing.T) {
+	tests := []struct {
+		set  string
+		want string
+	}{
+		{"", "general"},
+		{"general", "general"},
+		{"unique", "unique"},
+		{"average", "average"},
+	}
+	for _, tt := range tests {
+		for _, method := range []string{"", "top"} {
+			params := map[string]string{"event": `["Signup"]`, "unit": "day", "interval": "7"}
+			if tt.set != "" {
+				params["type"] = tt.set
+			}
+			req := NewRequestWithConfig(frozenConfig())
+			get := req.GetEvents
+			if method == "top" {
+				get = req.GetEventsTop
+			}
+			uri, err := get(params)
+			if err != nil {
+				t.Fatalf("type %q, method %q: %v", tt.set, method, err)
+			}
+			q := queryParams(t, uri)
+			if q["type"] != tt.want {
+				t.Errorf("type %q, method %q: URL type = %q, want %q", tt.set, method, q["type"], tt.want)
+			}
+			if req.Parameters["type"] != tt.want || !VerifySignature(q, "secret", q["sig"]) {
+				t.Errorf("type %q, method %q: type %q not signed", tt.set, method, tt.want)
+			}
+		}
+	}
+}
+
+func TestEventsTypeInvalid(t *testing.T) {
+	req := NewRequestWithConfig(frozenConfig())
+	params := map[string]string{"event": `["Signup"]`, "type": "total"}
+	if _, err := req.GetEvents(params); err == nil {
+		t.Error("GetEvents accepted type total")
+	}
+	if _, err := req.GetEventsTop(params); err == nil {
+		t.Error("GetEventsTop accepted type total")
+	}
+	if err := (Params{}).SetEventType("total"); err == nil {
+		t.Error("SetEventType accepted total")
+	}
+}
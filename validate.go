@@ -0,0 +1,110 @@
+package mixpanel
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// requiredParams lists the parameters that must be set for each request,
+// keyed as "endpoint" or "endpoint/method".
+var requiredParams = map[string][]string{
+	"events/properties":        {"event", "name", "type", "unit"},
+	"events/properties/top":    {"event"},
+	"events/properties/values": {"event", "name"},
+	"segmentation":             {"event", "from_date", "to_date"},
+	"segmentation/sum":         {"event", "on", "from_date", "to_date"},
+	"segmentation/average":     {"event", "on", "from_date", "to_date"},
+	"retention":                {"from_date", "to_date", "retention_type"},
+	"retention/addiction":      {"from_date", "to_date", "unit", "addiction_unit"},
+	"funnels":                  {"funnel_id", "from_date", "to_date"},
+	"export":                   {"from_date", "to_date"},
+	"engage/revenue":           {"from_date", "to_date"},
+	"insights":                 {"bookmark_id"},
+	"stream/query":             {"from_date", "to_date"},
+}
+
+// postEndpoints lists the requests, keyed like requiredParams, that must be
+// sent as a POST with a form body.
+var postEndpoints = map[string]bool{
+	"jql":                true,
+	"cohorts/list":       true,
+	"annotations/create": true,
+	"annotations/delete": true,
+}
+
+// requestName returns the key of endpoint and method in requiredParams.
+func requestName(endpoint string, method string) string {
+	if method == "" {
+		return endpoint
+	}
+	return endpoint + "/" + method
+}
+
+// checkRequired returns an error naming the first required parameter of
+// endpoint and method that is missing or empty in params.
+func checkRequired(endpoint string, method string, params map[string]string) error {
+	name := requestName(endpoint, method)
+	for _, key := range requiredParams[name] {
+		if params[key] == "" {
+			return fmt.Errorf("mixpanel: missing required parameter %q for %s", key, name)
+		}
+	}
+	return nil
+}
+
+// checkEvents validates the `type` and `interval` parameters of an events
+// request.
+func checkEvents(params map[string]string) error {
+	switch t := params["type"]; t {
+	case "", string(EventsGeneral), string(EventsUnique), string(EventsAverage):
+	default:
+		return fmt.Errorf("mixpanel: invalid events type %q", t)
+	}
+	if v, ok := params["interval"]; ok {
+		if n, err := strconv.Atoi(v); err != nil || n < 1 {
+			return fmt.Errorf("mixpanel: invalid interval %q", v)
+		}
+	}
+	return nil
+}
+
+// checkWorkspaceID returns an error if WorkspaceID is set but not numeric.
+// It is checked wherever the id is sent, so that no request carries it
+// unvalidated.
+func (c *Config) checkWorkspaceID() error {
+	if c.WorkspaceID == "" {
+		return nil
+	}
+	if _, err := strconv.ParseUint(c.WorkspaceID, 10, 64); err != nil {
+		return fmt.Errorf("mixpanel: workspace_id %q is not numeric", c.WorkspaceID)
+	}
+	return nil
+}
+
+// checkServiceAccount returns an error unless a service account is
+// configured, for the APIs that accept no other credentials.
+func (c *Config) checkServiceAccount() error {
+	if !c.usesServiceAccount() {
+		return errors.New("mixpanel: missing service account")
+	}
+	return nil
+}
+
+// createChecked is CreateRequest with the default expiry, after validating
+// the required parameters of endpoint and method and the configured format.
+func (req *Request) createChecked(raw bool, endpoint string, method string, params map[string]string) (string, error) {
+	if err := checkRequired(endpoint, method, params); err != nil {
+		return "", err
+	}
+	if err := req.checkFormat(); err != nil {
+		return "", err
+	}
+	if err := req.checkWorkspaceID(); err != nil {
+		return "", err
+	}
+	if !raw && req.usesQueryAPI() && (req.ProjectID == "" || !req.usesServiceAccount()) {
+		return "", errors.New("mixpanel: the query API requires a service account and project_id")
+	}
+	return req.CreateRequest(raw, endpoint, method, 0, params), nil
+}
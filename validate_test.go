@@ -0,0 +1,84 @@
+package mixpanel
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWorkspaceIDValidated(t *testing.T) {
+	hits := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+	c := NewClient(WithCredentials("key", "secret"), WithEndpoint(srv.URL, srv.URL),
+		WithServiceAccount("user", "secret", "1"), WithWorkspaceID("12 OR 1"))
+
+	if _, err := c.Query(context.Background(), "events", "names", map[string]string{"type": "general"}); err == nil {
+		t.Error("Query sent a non-numeric workspace_id")
+	}
+	req := c.NewRequest()
+	if _, err := req.GetFunnelsList(); err == nil {
+		t.Error("GetFunnelsList accepted a non-numeric workspace_id")
+	}
+	req.CreateRequest(false, "funnels", "list", 0, nil)
+	if _, err := req.Do(false); err == nil {
+		t.Error("Do sent a non-numeric workspace_id")
+	}
+	if _, err := req.GetEngage(nil); err == nil {
+		t.Error("GetEngage accepted a non-numeric workspace_id")
+	}
+	if _, err := c.ListBookmarks(); err == nil {
+		t.Error("ListBookmarks accepted a non-numeric workspace_id")
+	}
+	if hits != 0 {
+		t.Errorf("%d requests reached the server", hits)
+	}
+}
+
+func TestInvalidFormatReported(t *testing.T) {
+	cfg := frozenConfig()
+	cfg.Format = "xml"
+	req := NewRequestWithConfig(cfg)
+	if _, err := req.GetEventsNames(map[string]string{"type": "general"}); err == nil {
+		t.Error("GetEventsNames accepted format xml")
+	}
+	if _, err := req.GetFunnelsList(); err == nil {
+		t.Error("GetFunnelsList accepted format xml")
+	}
+	if _, err := req.GetAnnotations("2020-01-01", "2020-01-31"); err == nil {
+		t.Error("GetAnnotations accepted format xml")
+	}
+	if uri := req.CreateRequest(false, "funnels", "list", 0, nil); uri == "" {
+		t.Error("CreateRequest returned an empty URL")
+	}
+	if _, err := req.URL(false); err == nil {
+		t.Error("URL accepted format xml")
+	}
+}
+
+func TestServiceAccountRequired(t *testing.T) {
+	hits := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		w.Write([]byte(`{"results":[]}`))
+	}))
+	defer srv.Close()
+	c := NewClient(WithConfig(Config{APIKey: "key", APISecret: "secret", ProjectID: "1", QueryEndpoint: srv.URL}))
+
+	if _, err := c.ListBookmarks(); err == nil {
+		t.Error("ListBookmarks sent a request without a service account")
+	}
+	if _, err := c.GetLexiconSchemas(); err == nil {
+		t.Error("GetLexiconSchemas sent a request without a service account")
+	}
+	if err := c.SetLexiconSchema(LexiconEvent, LexiconSchema{Name: "Signup"}); err == nil {
+		t.Error("SetLexiconSchema sent a request without a service account")
+	}
+	if hits != 0 {
+		t.Errorf("%d requests reached the server, want 0", hits)
+	}
+}
@@ -0,0 +1,89 @@
+package mixpanel
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// quote returns s as a double-quoted, escaped expression string literal.
+func quote(s string) string {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.Encode(s)
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+// literal returns v as an expression literal: strings are quoted, booleans
+// and numbers are written as-is.
+func literal(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return quote(v)
+	case bool, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		return fmt.Sprint(v)
+	}
+	return quote(fmt.Sprint(v))
+}
+
+// Property returns the expression referring to the event property name, e.g.
+// properties["$city"].
+func Property(name string) string {
+	return "properties[" + quote(name) + "]"
+}
+
+func compare(name string, op string, value interface{}) string {
+	return Property(name) + " " + op + " " + literal(value)
+}
+
+// WhereEq returns an expression testing that property name equals value.
+func WhereEq(name string, value interface{}) string { return compare(name, "==", value) }
+
+// WhereNe returns an expression testing that property name does not equal value.
+func WhereNe(name string, value interface{}) string { return compare(name, "!=", value) }
+
+// WhereGt returns an expression testing that property name is greater than value.
+func WhereGt(name string, value interface{}) string { return compare(name, ">", value) }
+
+// WhereGe returns an expression testing that property name is at least value.
+func WhereGe(name string, value interface{}) string { return compare(name, ">=", value) }
+
+// WhereLt returns an expression testing that property name is less than value.
+func WhereLt(name string, value interface{}) string { return compare(name, "<", value) }
+
+// WhereLe returns an expression testing that property name is at most value.
+func WhereLe(name string, value interface{}) string { return compare(name, "<=", value) }
+
+// WhereIn returns an expression testing that value is in the list or string
+// property name.
+func WhereIn(name string, value interface{}) string {
+	return literal(value) + " in " + Property(name)
+}
+
+// WhereDefined returns an expression testing that property name is set.
+func WhereDefined(name string) string {
+	return "defined(" + Property(name) + ")"
+}
+
+// WhereAnd joins exprs so that all must hold.
+func WhereAnd(exprs ...string) string { return join(" and ", exprs) }
+
+// WhereOr joins exprs so that any must hold.
+func WhereOr(exprs ...string) string { return join(" or ", exprs) }
+
+// WhereNot negates expr.
+func WhereNot(expr string) string { return "not (" + expr + ")" }
+
+// join parenthesizes exprs and joins them with op.
+func join(op string, exprs []string) string {
+	if len(exprs) == 1 {
+		return exprs[0]
+	}
+	parts := make([]string, len(exprs))
+	for i, e := range exprs {
+		parts[i] = "(" + e + ")"
+	}
+	return strings.Join(parts, op)
+}